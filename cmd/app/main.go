@@ -138,6 +138,8 @@ func adaptor(h http.Handler) fiber.Handler {
 }
 
 func main() {
+	env.Load()
+
 	// Initialize server and database
 	server := server.NewHttpServer()
 	psqlDB := database.NewPgsqlConn()
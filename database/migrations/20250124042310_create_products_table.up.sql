@@ -0,0 +1,16 @@
+CREATE TABLE products (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(32) NOT NULL,
+    category INT NOT NULL,
+    qty INT NOT NULL CHECK (qty >= 0),
+    price NUMERIC(15, 2) NOT NULL,
+    sku VARCHAR(32) NOT NULL,
+    file_id INT NOT NULL REFERENCES files(id),
+    file_url VARCHAR(255) NOT NULL DEFAULT '',
+    file_thumbnail_url VARCHAR(255) NOT NULL DEFAULT '',
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_products_user_id ON products(user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE files
+ADD COLUMN user_id INT NULL REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_files_user_id ON files(user_id);
@@ -0,0 +1,14 @@
+ALTER TABLE purchase
+ADD COLUMN purchased_items_array JSONB[];
+
+UPDATE purchase
+SET purchased_items_array = ARRAY(SELECT jsonb_array_elements(purchased_items));
+
+ALTER TABLE purchase
+DROP COLUMN purchased_items;
+
+ALTER TABLE purchase
+RENAME COLUMN purchased_items_array TO purchased_items;
+
+ALTER TABLE purchase
+ALTER COLUMN purchased_items SET NOT NULL;
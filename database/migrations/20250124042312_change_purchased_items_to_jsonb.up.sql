@@ -0,0 +1,2 @@
+ALTER TABLE purchase
+ALTER COLUMN purchased_items TYPE JSONB USING array_to_json(purchased_items)::jsonb;
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_purchase_status_expires_at;
+
+ALTER TABLE purchase
+DROP COLUMN IF EXISTS expires_at,
+DROP COLUMN IF EXISTS status;
+
+ALTER TABLE products
+DROP COLUMN IF EXISTS reserved_qty;
@@ -0,0 +1,8 @@
+ALTER TABLE products
+ADD COLUMN reserved_qty INT NOT NULL DEFAULT 0 CHECK (reserved_qty >= 0);
+
+ALTER TABLE purchase
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending',
+ADD COLUMN expires_at TIMESTAMP NULL;
+
+CREATE INDEX idx_purchase_status_expires_at ON purchase(status, expires_at);
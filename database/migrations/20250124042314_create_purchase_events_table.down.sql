@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS purchase_events;
+
+ALTER TABLE purchase
+DROP CONSTRAINT IF EXISTS chk_purchase_status;
@@ -0,0 +1,18 @@
+ALTER TABLE purchase
+ADD CONSTRAINT chk_purchase_status
+CHECK (status IN ('pending', 'paid', 'confirmed', 'shipped', 'completed', 'cancelled', 'expired'));
+
+CREATE TABLE purchase_events (
+    id SERIAL PRIMARY KEY,
+    purchase_id INT NOT NULL REFERENCES purchase(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NULL,
+    to_status VARCHAR(20) NOT NULL,
+    actor_user_id INT NULL REFERENCES users(id) ON DELETE SET NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_purchase_events_purchase_id ON purchase_events(purchase_id);
+
+INSERT INTO purchase_events (purchase_id, to_status, created_at)
+SELECT id, status, created_at FROM purchase;
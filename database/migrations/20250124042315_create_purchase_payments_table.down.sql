@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS purchase_payment_proofs;
+DROP TABLE IF EXISTS purchase_payments;
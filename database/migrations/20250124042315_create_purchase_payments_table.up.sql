@@ -0,0 +1,21 @@
+CREATE TABLE purchase_payments (
+    id SERIAL PRIMARY KEY,
+    purchase_id INT NOT NULL REFERENCES purchase(id) ON DELETE CASCADE,
+    seller_id INT NOT NULL REFERENCES users(id),
+    bank_account_name VARCHAR(32) NOT NULL DEFAULT '',
+    bank_account_holder VARCHAR(32) NOT NULL DEFAULT '',
+    bank_account_number VARCHAR(32) NOT NULL DEFAULT '',
+    total_price NUMERIC(15, 2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (purchase_id, seller_id)
+);
+
+CREATE TABLE purchase_payment_proofs (
+    id SERIAL PRIMARY KEY,
+    purchase_payment_id INT NOT NULL REFERENCES purchase_payments(id) ON DELETE CASCADE,
+    file_id INT NOT NULL REFERENCES files(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (purchase_payment_id, file_id)
+);
@@ -0,0 +1,34 @@
+CREATE TABLE purchase_items (
+    id SERIAL PRIMARY KEY,
+    purchase_id INT NOT NULL REFERENCES purchase(id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    seller_id INT NOT NULL REFERENCES users(id),
+    name VARCHAR(32) NOT NULL,
+    category INT NOT NULL,
+    qty INT NOT NULL,
+    price NUMERIC(15, 2) NOT NULL,
+    sku VARCHAR(32) NOT NULL DEFAULT '',
+    file_id INT,
+    file_url VARCHAR(255) NOT NULL DEFAULT '',
+    file_thumbnail_url VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_purchase_items_seller_id ON purchase_items (seller_id, purchase_id);
+CREATE INDEX idx_purchase_items_purchase_id ON purchase_items (purchase_id);
+
+INSERT INTO purchase_items (purchase_id, product_id, seller_id, name, category, qty, price, sku, file_id, file_url, file_thumbnail_url, created_at)
+SELECT p.id,
+       (item->>'productId')::INT,
+       (item->>'sellerId')::INT,
+       item->>'name',
+       (item->>'category')::INT,
+       (item->>'qty')::INT,
+       (item->>'price')::NUMERIC,
+       COALESCE(item->>'sku', ''),
+       NULLIF(item->>'fileId', '')::INT,
+       COALESCE(item->>'fileUri', ''),
+       COALESCE(item->>'fileThumbnailUri', ''),
+       p.created_at
+FROM purchase p, jsonb_array_elements(p.purchased_items) AS item
+WHERE (item->>'sellerId')::INT IN (SELECT id FROM users);
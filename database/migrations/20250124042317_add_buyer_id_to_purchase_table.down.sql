@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_purchase_buyer_id;
+
+ALTER TABLE purchase DROP COLUMN IF EXISTS buyer_id;
@@ -0,0 +1,3 @@
+ALTER TABLE purchase ADD COLUMN buyer_id INT REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_purchase_buyer_id ON purchase (buyer_id, created_at DESC);
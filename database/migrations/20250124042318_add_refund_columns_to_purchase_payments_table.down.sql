@@ -0,0 +1,3 @@
+ALTER TABLE purchase_payments
+    DROP COLUMN IF EXISTS refunded_by,
+    DROP COLUMN IF EXISTS refunded_at;
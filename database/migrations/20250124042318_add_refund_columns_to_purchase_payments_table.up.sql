@@ -0,0 +1,3 @@
+ALTER TABLE purchase_payments
+    ADD COLUMN refunded_at TIMESTAMP,
+    ADD COLUMN refunded_by INT REFERENCES users(id) ON DELETE SET NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE purchase_payments
+    DROP COLUMN IF EXISTS voucher_code,
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS subtotal;
+
+DROP TABLE IF EXISTS voucher_redemptions;
+DROP TABLE IF EXISTS vouchers;
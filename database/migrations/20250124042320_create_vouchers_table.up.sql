@@ -0,0 +1,42 @@
+CREATE TABLE vouchers (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    seller_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    percent_off INT NOT NULL DEFAULT 0 CHECK (percent_off BETWEEN 0 AND 100),
+    amount_off NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    max_discount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    min_spend NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    category INT,
+    usage_limit INT,
+    per_user_limit INT,
+    used_count INT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_vouchers_seller_id ON vouchers (seller_id);
+
+CREATE TABLE voucher_redemptions (
+    id SERIAL PRIMARY KEY,
+    voucher_id INT NOT NULL REFERENCES vouchers(id) ON DELETE CASCADE,
+    purchase_id INT NOT NULL REFERENCES purchase(id) ON DELETE CASCADE,
+    buyer_id INT REFERENCES users(id) ON DELETE SET NULL,
+    buyer_contact VARCHAR(255) NOT NULL,
+    discount NUMERIC(15, 2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_voucher_redemptions_voucher_id ON voucher_redemptions (voucher_id);
+CREATE INDEX idx_voucher_redemptions_purchase_id ON voucher_redemptions (purchase_id);
+
+ALTER TABLE purchase_payments
+    ADD COLUMN subtotal NUMERIC(15, 2),
+    ADD COLUMN discount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN voucher_code VARCHAR(32) NOT NULL DEFAULT '';
+
+UPDATE purchase_payments SET subtotal = total_price;
+
+ALTER TABLE purchase_payments ALTER COLUMN subtotal SET NOT NULL;
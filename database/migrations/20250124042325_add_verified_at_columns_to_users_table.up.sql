@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMP NULL,
+    ADD COLUMN phone_verified_at TIMESTAMP NULL;
+
+-- Accounts created before contacts were verified keep being able to log in
+UPDATE users SET email_verified_at = created_at WHERE email IS NOT NULL;
+UPDATE users SET phone_verified_at = created_at WHERE phone IS NOT NULL;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_files_purchase_id;
+
+ALTER TABLE files
+DROP COLUMN IF EXISTS purchase_id;
@@ -0,0 +1,4 @@
+ALTER TABLE files
+ADD COLUMN purchase_id INT NULL REFERENCES purchase(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_files_purchase_id ON files(purchase_id);
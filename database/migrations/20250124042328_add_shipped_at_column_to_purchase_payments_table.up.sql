@@ -0,0 +1,2 @@
+ALTER TABLE purchase_payments
+    ADD COLUMN shipped_at TIMESTAMP;
@@ -0,0 +1,2 @@
+ALTER TABLE purchase_payments
+    DROP COLUMN IF EXISTS note;
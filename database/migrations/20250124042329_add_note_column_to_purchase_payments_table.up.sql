@@ -0,0 +1,2 @@
+ALTER TABLE purchase_payments
+    ADD COLUMN note VARCHAR(255) NOT NULL DEFAULT '';
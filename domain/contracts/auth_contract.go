@@ -12,11 +12,33 @@ type AuthRepository interface {
 	FindByPhone(ctx context.Context, phone string) (*entity.User, error)
 	RegisterWithEmail(ctx context.Context, user *entity.User) error
 	RegisterWithPhone(ctx context.Context, user *entity.User) error
+	UpdatePassword(ctx context.Context, userID int, password string) error
+	// MarkEmailVerified and MarkPhoneVerified do nothing when the contact of
+	// the user has changed since the code was sent
+	MarkEmailVerified(ctx context.Context, userID int, email string) error
+	MarkPhoneVerified(ctx context.Context, userID int, phone string) error
 }
 
 type AuthService interface {
-	LoginWithEmail(ctx context.Context, req *dto.LoginWithEmailRequest) (*dto.LoginWithEmailResponse, error)
-	LoginWithPhone(ctx context.Context, req *dto.LoginWithPhoneRequest) (*dto.LoginWithPhoneResponse, error)
+	LoginWithEmail(ctx context.Context, req *dto.LoginWithEmailRequest, client dto.ClientInfo) (*dto.LoginWithEmailResponse, error)
+	LoginWithPhone(ctx context.Context, req *dto.LoginWithPhoneRequest, client dto.ClientInfo) (*dto.LoginWithPhoneResponse, error)
+	// RegisterWithEmail and RegisterWithPhone create the account and send a
+	// verification code, no tokens are issued until the contact is verified
 	RegisterWithEmail(ctx context.Context, req *dto.RegisterWithEmailRequest) (*dto.RegisterWithEmailResponse, error)
 	RegisterWithPhone(ctx context.Context, req *dto.RegisterWithPhoneRequest) (*dto.RegisterWithPhoneResponse, error)
+	// Refresh rotates a refresh token. Using a rotated token again revokes the
+	// whole session, since either the client or an attacker holds a stolen copy.
+	Refresh(ctx context.Context, req *dto.RefreshTokenRequest, client dto.ClientInfo) (*dto.RefreshTokenResponse, error)
+	Logout(ctx context.Context, sessionID string) error
+	// ForgotPassword sends a reset code when the email or phone is registered.
+	// It never tells whether it is, so accounts can't be enumerated with it.
+	ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error
+	// ResetPassword sets a new password and signs out every session
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+	// SendVerification sends a code to an unverified email or phone, without
+	// telling whether it is registered, like ForgotPassword
+	SendVerification(ctx context.Context, req *dto.SendVerificationRequest) error
+	// VerifyContact marks the email or phone as verified, after which it can
+	// be used to log in and to reset the password
+	VerifyContact(ctx context.Context, req *dto.VerifyContactRequest) error
 }
@@ -0,0 +1,33 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+type CartRepository interface {
+	FindByID(ctx context.Context, id int) (*entity.Cart, error)
+	FindByUserID(ctx context.Context, userID int) (*entity.Cart, error)
+	Create(ctx context.Context, cart *entity.Cart) error
+	AssignUser(ctx context.Context, cartID int, userID int) error
+	Delete(ctx context.Context, id int) error
+	GetItems(ctx context.Context, cartID int) ([]entity.CartItem, error)
+	AddItem(ctx context.Context, item *entity.CartItem) error
+	UpdateItem(ctx context.Context, item *entity.CartItem) error
+	DeleteItem(ctx context.Context, cartID int, productID int) error
+	ClearItems(ctx context.Context, cartID int) error
+	MergeItems(ctx context.Context, fromCartID int, toCartID int) error
+	GetProductsByIDs(ctx context.Context, ids []int) ([]entity.Product, error)
+}
+
+// CartService keeps the cart of a logged in user, or of a guest identified by
+// cartToken. A guest cart sent along by a logged in user is merged into theirs.
+type CartService interface {
+	GetCart(ctx context.Context, userID int, cartToken string) (*dto.CartResponse, error)
+	AddItem(ctx context.Context, userID int, cartToken string, req *dto.AddCartItemRequest) (*dto.CartResponse, error)
+	UpdateItem(ctx context.Context, userID int, cartToken string, productID string, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error)
+	RemoveItem(ctx context.Context, userID int, cartToken string, productID string) (*dto.CartResponse, error)
+	Checkout(ctx context.Context, userID int, cartToken string, req *dto.CheckoutCartRequest) (dto.PurchaseResponse, error)
+}
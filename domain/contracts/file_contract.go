@@ -0,0 +1,30 @@
+package contracts
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// FileStorage is the place where uploaded file contents end up
+type FileStorage interface {
+	// Put stores the content under key and returns the URI it can be fetched from
+	Put(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type FileRepository interface {
+	FindByID(ctx context.Context, id int) (*entity.File, error)
+	Create(ctx context.Context, file *entity.File) error
+}
+
+type FileService interface {
+	Upload(ctx context.Context, userID int, fileHeader *multipart.FileHeader) (*dto.UploadFileResponse, error)
+	UploadForPurchase(ctx context.Context, purchaseID string, lookupToken string, fileHeader *multipart.FileHeader) (*dto.UploadFileResponse, error)
+	GetFile(ctx context.Context, fileID string) (*entity.File, error)
+	GetOwnedFile(ctx context.Context, userID int, fileID string) (*entity.File, error)
+	GetPurchaseFile(ctx context.Context, purchaseID int, fileID string) (*entity.File, error)
+}
@@ -0,0 +1,19 @@
+package contracts
+
+import (
+	"context"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+type IdempotencyRepository interface {
+	// Claim stores the key unless it is already in use. Keys older than ttl
+	// are claimed again. It reports whether the key was claimed.
+	Claim(ctx context.Context, key *entity.IdempotencyKey, ttl time.Duration) (bool, error)
+	FindByKey(ctx context.Context, key, scope string) (entity.IdempotencyKey, error)
+	Complete(ctx context.Context, key *entity.IdempotencyKey) error
+	Release(ctx context.Context, key, scope string) error
+	// DeleteExpired removes the keys older than ttl and returns how many
+	DeleteExpired(ctx context.Context, ttl time.Duration) (int64, error)
+}
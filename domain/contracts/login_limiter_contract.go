@@ -0,0 +1,21 @@
+package contracts
+
+import (
+	"context"
+	"time"
+)
+
+// LoginLimiter slows down password guessing by locking out a key, e.g. an
+// email address or an IP address, for longer with every failed login
+type LoginLimiter interface {
+	// Blocked returns how long key has to wait before it may try again, zero
+	// when it isn't locked out
+	Blocked(ctx context.Context, key string) (time.Duration, error)
+	// Fail counts a failed login of key and returns the lockout it caused
+	Fail(ctx context.Context, key string) (time.Duration, error)
+	// Reset forgets the failures of key
+	Reset(ctx context.Context, key string) error
+	// Prune forgets the keys that are neither locked out nor failed within
+	// the window any more
+	Prune(ctx context.Context) error
+}
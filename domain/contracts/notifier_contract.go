@@ -0,0 +1,12 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+)
+
+// Notifier delivers messages to users by email or SMS
+type Notifier interface {
+	Send(ctx context.Context, notification *dto.Notification) error
+}
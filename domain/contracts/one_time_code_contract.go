@@ -0,0 +1,39 @@
+package contracts
+
+import (
+	"context"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+type OneTimeCodeRepository interface {
+	Create(ctx context.Context, code *entity.OneTimeCode, ttl time.Duration) error
+	// IssuedWithin reports whether the user was sent a code for purpose less
+	// than interval ago
+	IssuedWithin(ctx context.Context, userID int, purpose string, interval time.Duration) (bool, error)
+	// LockActive finds the latest unused and unexpired code and locks it until
+	// the transaction ends
+	LockActive(ctx context.Context, userID int, purpose string, target string) (*entity.OneTimeCode, error)
+	IncrementAttempts(ctx context.Context, id int) error
+	MarkUsed(ctx context.Context, id int) error
+	// Invalidate marks every unused code of the user for purpose as used
+	Invalidate(ctx context.Context, userID int, purpose string) error
+	// ReleaseUnverifiedContact takes target away from a user who never
+	// verified it once the first code sent for it has expired
+	ReleaseUnverifiedContact(ctx context.Context, purpose string, target string) error
+}
+
+// OneTimeCodeService sends short numeric codes that prove a user controls an
+// email address or phone number
+type OneTimeCodeService interface {
+	// Issue sends a new code to target, earlier codes for purpose stop working
+	Issue(ctx context.Context, userID int, purpose string, channel string, target string) error
+	// Verify uses up the code. Every wrong guess counts against the code, which
+	// stops working after a few of them.
+	Verify(ctx context.Context, userID int, purpose string, target string, code string) error
+	// ReleaseUnverified frees the email or phone of a verification purpose
+	// when its holder let the first code sent for it expire, so resending
+	// codes can't hold it and its actual owner can claim it
+	ReleaseUnverified(ctx context.Context, purpose string, target string) error
+}
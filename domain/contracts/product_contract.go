@@ -0,0 +1,23 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+type ProductRepository interface {
+	FindAll(ctx context.Context, query *dto.GetProductsQuery) ([]entity.Product, int, error)
+	FindByID(ctx context.Context, id int) (*entity.Product, error)
+	Create(ctx context.Context, product *entity.Product) error
+	Update(ctx context.Context, product *entity.Product) error
+	Delete(ctx context.Context, id int) error
+}
+
+type ProductService interface {
+	GetProducts(ctx context.Context, query *dto.GetProductsQuery) (*dto.PaginatedResponse[dto.ProductResponse], error)
+	CreateProduct(ctx context.Context, userID int, req *dto.CreateProductRequest) (*dto.ProductResponse, error)
+	UpdateProduct(ctx context.Context, userID int, productID string, req *dto.UpdateProductRequest) (*dto.ProductResponse, error)
+	DeleteProduct(ctx context.Context, userID int, productID string) error
+}
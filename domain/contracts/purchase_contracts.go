@@ -2,20 +2,55 @@ package contracts
 
 import (
 	"context"
+	"time"
 
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
 )
 
 type PurchaseRepository interface {
-	CreatePurchase(ctx context.Context, purchasedItems []entity.PurchaseItem, senderName string, senderContactType string, senderContactDetail string) (int64, error)
-	DecreaseQuantity(ctx context.Context, productId int, quantity int) error
+	CreatePurchase(ctx context.Context, purchase *entity.Purchase, reservationTTL time.Duration) error
+	CreatePurchaseItems(ctx context.Context, purchaseId int, items []entity.PurchaseItem) error
+	ReserveQuantity(ctx context.Context, productId int, quantity int) error
+	CommitReservedQuantity(ctx context.Context, productId int, quantity int) error
+	ReleaseReservedQuantity(ctx context.Context, productId int, quantity int) error
+	RestoreReservedQuantity(ctx context.Context, productId int, quantity int) error
+	RestockQuantity(ctx context.Context, productId int, quantity int) error
 	GetProductById(ctx context.Context, productId int) (entity.Product, error)
-	GetSellerById(ctx context.Context, sellerId int) (entity.DummyUser, error)
+	GetSellerById(ctx context.Context, sellerId int) (entity.User, error)
 	GetPurchaseById(ctx context.Context, purchaseId int) (entity.Purchase, error)
+	GetBuyerPurchases(ctx context.Context, buyerId int, query *dto.GetPurchasesQuery) ([]entity.Purchase, int, error)
+	LockPurchaseById(ctx context.Context, purchaseId int) (entity.Purchase, error)
+	LockExpiredPurchases(ctx context.Context, limit int) ([]entity.Purchase, error)
+	UpdatePurchaseStatus(ctx context.Context, purchaseId int, status string) error
+	ExtendReservation(ctx context.Context, purchaseId int, reservationTTL time.Duration) error
+	GetSellerPurchases(ctx context.Context, sellerId int, query *dto.GetSellerOrdersQuery) ([]entity.Purchase, int, error)
+	GetSellerPurchaseItems(ctx context.Context, sellerId int, purchaseIds []int) ([]entity.PurchaseItem, error)
+	GetSellerPayments(ctx context.Context, sellerId int, purchaseIds []int) ([]entity.PurchasePayment, error)
+	CreatePurchasePayment(ctx context.Context, payment *entity.PurchasePayment) error
+	GetPurchasePayments(ctx context.Context, purchaseId int) ([]entity.PurchasePayment, error)
+	GetPaymentsByPurchaseIds(ctx context.Context, purchaseIds []int) ([]entity.PurchasePayment, error)
+	GetPaymentProofsByPurchaseIds(ctx context.Context, purchaseIds []int) ([]entity.PurchasePaymentProof, error)
+	UpdatePurchasePaymentStatus(ctx context.Context, paymentId int, status string, note string) error
+	RefundPurchasePayment(ctx context.Context, paymentId int, refundedBy int, reason string) error
+	MarkPaymentShipped(ctx context.Context, paymentId int) error
+	CreatePaymentProofs(ctx context.Context, paymentId int, fileIds []int) error
+	CreatePurchaseEvent(ctx context.Context, event *entity.PurchaseEvent) error
+	GetPurchaseEvents(ctx context.Context, purchaseId int) ([]entity.PurchaseEvent, error)
 }
 
 type PurchaseService interface {
-	Purchase(ctx context.Context, req dto.PurchaseRequest) (dto.PurchaseResponse, error)
-	UploadPayment(ctx context.Context, req dto.UploadPaymentRequest, purchaseId string) error
+	Purchase(ctx context.Context, buyerId int, req dto.PurchaseRequest) (dto.PurchaseResponse, error)
+	GetPurchases(ctx context.Context, buyerId int, query *dto.GetPurchasesQuery) (*dto.PaginatedResponse[dto.PurchaseResponse], error)
+	GetPurchase(ctx context.Context, userId int, purchaseId string, lookupToken string) (dto.PurchaseResponse, error)
+	UploadPayment(ctx context.Context, userId int, purchaseId string, lookupToken string, req dto.UploadPaymentRequest) error
+	UpdateStatus(ctx context.Context, userId int, purchaseId string, req dto.UpdatePurchaseStatusRequest) error
+	GetEvents(ctx context.Context, userId int, purchaseId string, lookupToken string) ([]dto.PurchaseEventResponse, error)
+	CancelPurchase(ctx context.Context, userId int, purchaseId string, lookupToken string, req dto.CancelPurchaseRequest) error
+	CompletePurchase(ctx context.Context, userId int, purchaseId string, lookupToken string) error
+	ReleaseExpiredReservations(ctx context.Context) (int, error)
+	GetSellerOrders(ctx context.Context, sellerId int, query *dto.GetSellerOrdersQuery) (*dto.PaginatedResponse[dto.SellerOrderResponse], error)
+	ConfirmPayment(ctx context.Context, sellerId int, purchaseId string) error
+	RejectPayment(ctx context.Context, sellerId int, purchaseId string, req dto.RejectPaymentRequest) error
+	RefundPayment(ctx context.Context, sellerId int, purchaseId string, req dto.RefundPaymentRequest) error
 }
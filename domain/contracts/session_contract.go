@@ -0,0 +1,25 @@
+package contracts
+
+import (
+	"context"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+	FindByID(ctx context.Context, id string) (*entity.Session, error)
+	FindActiveByUserID(ctx context.Context, userID int) ([]entity.Session, error)
+	IsActive(ctx context.Context, id string) (bool, error)
+	Touch(ctx context.Context, id string, ipAddress string) error
+	Revoke(ctx context.Context, id string, reason string) error
+	// RevokeByUserID revokes every session of the user except keepID, which
+	// may be empty to revoke them all
+	RevokeByUserID(ctx context.Context, userID int, keepID string, reason string) error
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken, ttl time.Duration) error
+	// LockRefreshToken finds an unexpired refresh token by its hash and locks it
+	// until the transaction ends
+	LockRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id int) error
+}
@@ -0,0 +1,10 @@
+package contracts
+
+import "context"
+
+// UnitOfWork runs fn inside a single database transaction. Repositories called
+// with the context handed to fn take part in that transaction, so their writes
+// are committed or rolled back together.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
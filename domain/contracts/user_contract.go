@@ -13,6 +13,7 @@ type UserRepository interface {
 	FindByPhone(ctx context.Context, phone string) (*entity.User, error)
 	FindByEmailOrPhone(ctx context.Context, email, phone string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
+	UpdatePassword(ctx context.Context, userID int, password string) error
 }
 
 type UserService interface {
@@ -20,4 +21,10 @@ type UserService interface {
 	UpdateUser(ctx context.Context, id int, req *dto.UpdateUserRequest) (*dto.UpdateUserResponse, error)
 	LinkEmail(ctx context.Context, id int, req *dto.LinkEmailRequest) (*dto.LinkEmailResponse, error)
 	LinkPhone(ctx context.Context, id int, req *dto.LinkPhoneRequest) (*dto.LinkPhoneResponse, error)
+	GetSessions(ctx context.Context, id int, currentSessionID string) ([]dto.SessionResponse, error)
+	RevokeSession(ctx context.Context, id int, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, id int, currentSessionID string) error
+	// ChangePassword can sign out every session but the current one, for when
+	// the old password may have leaked
+	ChangePassword(ctx context.Context, id int, currentSessionID string, req *dto.ChangePasswordRequest) error
 }
@@ -0,0 +1,35 @@
+package contracts
+
+import (
+	"context"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+type VoucherRepository interface {
+	FindBySeller(ctx context.Context, sellerID int, query *dto.PaginationQuery) ([]entity.Voucher, int, error)
+	FindByID(ctx context.Context, id int) (*entity.Voucher, error)
+	LockByCode(ctx context.Context, code string) (*entity.Voucher, error)
+	ExistsByCode(ctx context.Context, code string) (bool, error)
+	Create(ctx context.Context, voucher *entity.Voucher) error
+	Delete(ctx context.Context, id int) error
+	CountRedemptions(ctx context.Context, voucherID int, buyerID int) (int, error)
+	CreateRedemption(ctx context.Context, redemption *entity.VoucherRedemption) error
+	DeleteRedemptions(ctx context.Context, purchaseID int) error
+	DeleteSellerRedemptions(ctx context.Context, purchaseID int, sellerID int) error
+}
+
+type VoucherService interface {
+	GetVouchers(ctx context.Context, sellerID int, query *dto.PaginationQuery) (*dto.PaginatedResponse[dto.VoucherResponse], error)
+	CreateVoucher(ctx context.Context, sellerID int, req *dto.CreateVoucherRequest) (*dto.VoucherResponse, error)
+	DeleteVoucher(ctx context.Context, sellerID int, voucherID string) error
+	// Redeem checks the voucher against the items and records its use. It has
+	// to run in the transaction that creates the purchase.
+	Redeem(ctx context.Context, req *dto.RedeemVoucherRequest) (*dto.DiscountLine, error)
+	// ReleaseRedemptions gives the uses of a cancelled or expired purchase back
+	ReleaseRedemptions(ctx context.Context, purchaseID int) error
+	// ReleaseSellerRedemptions gives the use of the voucher of one seller back
+	// when that seller refunded their share of the purchase
+	ReleaseSellerRedemptions(ctx context.Context, purchaseID int, sellerID int) error
+}
@@ -1,14 +1,21 @@
 package dto
 
+// ClientInfo describes the device a session is started or refreshed from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
 type LoginWithEmailRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8,max=32"`
 }
 
 type LoginWithEmailResponse struct {
-	Email string `json:"email"`
-	Phone string `json:"phone"`
-	Token string `json:"token"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 type LoginWithPhoneRequest struct {
@@ -17,9 +24,10 @@ type LoginWithPhoneRequest struct {
 }
 
 type LoginWithPhoneResponse struct {
-	Email string `json:"email"`
-	Phone string `json:"phone"`
-	Token string `json:"token"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
 type RegisterWithEmailRequest struct {
@@ -27,10 +35,11 @@ type RegisterWithEmailRequest struct {
 	Password string `json:"password" validate:"required,min=8,max=32"`
 }
 
+// RegisterWithEmailResponse carries no tokens, the user logs in after
+// verifying the email with the code sent to it
 type RegisterWithEmailResponse struct {
 	Email string `json:"email"`
 	Phone string `json:"phone"`
-	Token string `json:"token"`
 }
 
 type RegisterWithPhoneRequest struct {
@@ -38,8 +47,43 @@ type RegisterWithPhoneRequest struct {
 	Password string `json:"password" validate:"required,min=8,max=32"`
 }
 
+// RegisterWithPhoneResponse carries no tokens, the user logs in after
+// verifying the phone with the code sent to it
 type RegisterWithPhoneResponse struct {
 	Email string `json:"email"`
 	Phone string `json:"phone"`
-	Token string `json:"token"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// ForgotPasswordRequest takes either the email or the phone of the account,
+// the code is sent to it
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required_without=Phone,excluded_with=Phone,omitempty,email"`
+	Phone string `json:"phone" validate:"required_without=Email,excluded_with=Email,omitempty,e164"`
+}
+
+type ResetPasswordRequest struct {
+	Email    string `json:"email" validate:"required_without=Phone,excluded_with=Phone,omitempty,email"`
+	Phone    string `json:"phone" validate:"required_without=Email,excluded_with=Email,omitempty,e164"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
+	Password string `json:"password" validate:"required,min=8,max=32"`
+}
+
+type SendVerificationRequest struct {
+	Email string `json:"email" validate:"required_without=Phone,excluded_with=Phone,omitempty,email"`
+	Phone string `json:"phone" validate:"required_without=Email,excluded_with=Email,omitempty,e164"`
+}
+
+type VerifyContactRequest struct {
+	Email string `json:"email" validate:"required_without=Phone,excluded_with=Phone,omitempty,email"`
+	Phone string `json:"phone" validate:"required_without=Email,excluded_with=Email,omitempty,e164"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
 }
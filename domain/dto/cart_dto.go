@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+
+// CartItemResponse is an item of the cart checked against the product as it
+// is now. AddedPrice is the price when the item was put in the cart.
+type CartItemResponse struct {
+	ProductID        string      `json:"productId"`
+	SellerID         string      `json:"sellerId"`
+	Name             string      `json:"name"`
+	Category         int         `json:"category"`
+	SKU              string      `json:"sku"`
+	FileThumbnailURI string      `json:"fileThumbnailUri"`
+	Qty              int         `json:"qty"`
+	AvailableQty     int         `json:"availableQty"`
+	InStock          bool        `json:"inStock"`
+	Price            money.Money `json:"price"`
+	AddedPrice       money.Money `json:"addedPrice"`
+	PriceChanged     bool        `json:"priceChanged"`
+	Subtotal         money.Money `json:"subtotal"`
+}
+
+// CartResponse is the cart of a user or a guest. CartToken is only set for
+// guests, who send it back in the X-Cart-Token header.
+type CartResponse struct {
+	CartToken   string             `json:"cartToken,omitempty"`
+	Items       []CartItemResponse `json:"items"`
+	TotalPrice  money.Money        `json:"totalPrice"`
+	Currency    string             `json:"currency"`
+	CanCheckout bool               `json:"canCheckout"`
+}
+
+// AddCartItemRequest takes the quantity rule of PurchaseItemRequest, an item
+// the purchase would turn down can't be put in the cart
+type AddCartItemRequest struct {
+	ProductID string `json:"productId" validate:"required,numeric"`
+	Qty       int    `json:"qty" validate:"required,min=2"`
+}
+
+type UpdateCartItemRequest struct {
+	Qty int `json:"qty" validate:"required,min=2"`
+}
+
+// CheckoutCartRequest turns the cart into a purchase, it takes the same
+// sender details as PurchaseRequest
+type CheckoutCartRequest struct {
+	SenderName          string `json:"sender_name" validate:"required,min=4,max=55"`
+	SenderContactType   string `json:"sender_contact_type" validate:"required,oneof=email phone"`
+	SenderContactDetail string `json:"sender_contact_detail" validate:"required"`
+	VoucherCode         string `json:"voucher_code" validate:"omitempty,alphanum,max=32"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+// The cart checks out through the purchase, so it has to accept exactly the
+// quantities a purchase accepts
+func TestCartQtyMatchesPurchaseQty(t *testing.T) {
+	for _, qty := range []int{-1, 0, 1, 2, 3, 100} {
+		purchaseErr := validator.Validator.Validate(PurchaseItemRequest{ProductID: "1", Qty: qty})
+		addErr := validator.Validator.Validate(AddCartItemRequest{ProductID: "1", Qty: qty})
+		updateErr := validator.Validator.Validate(UpdateCartItemRequest{Qty: qty})
+
+		if (addErr != nil) != (purchaseErr != nil) {
+			t.Errorf("qty %d: AddCartItemRequest error = %v, PurchaseItemRequest error = %v", qty, addErr, purchaseErr)
+		}
+
+		if (updateErr != nil) != (purchaseErr != nil) {
+			t.Errorf("qty %d: UpdateCartItemRequest error = %v, PurchaseItemRequest error = %v", qty, updateErr, purchaseErr)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package dto
+
+type UploadFileResponse struct {
+	FileID           string `json:"fileId"`
+	FileURI          string `json:"fileUri"`
+	FileThumbnailURI string `json:"fileThumbnailUri"`
+}
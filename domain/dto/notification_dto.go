@@ -0,0 +1,16 @@
+package dto
+
+// Notification channels, matching the contact types of users
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelPhone = "phone"
+)
+
+// Notification is a message for the email address or phone number in To.
+// Subject is only used for emails.
+type Notification struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
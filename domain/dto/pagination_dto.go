@@ -0,0 +1,38 @@
+package dto
+
+const (
+	DefaultPaginationLimit = 5
+	MaxPaginationLimit     = 100
+)
+
+type PaginationQuery struct {
+	Limit  int `query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int `query:"offset" validate:"omitempty,min=0"`
+}
+
+type PaginationMeta struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
+
+// PaginatedResponse is the envelope returned by every listing endpoint
+type PaginatedResponse[T any] struct {
+	Data []T            `json:"data"`
+	Meta PaginationMeta `json:"meta"`
+}
+
+// Normalize fills in the default limit when it is not provided
+func (q *PaginationQuery) Normalize() {
+	if q.Limit <= 0 {
+		q.Limit = DefaultPaginationLimit
+	}
+
+	if q.Limit > MaxPaginationLimit {
+		q.Limit = MaxPaginationLimit
+	}
+
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+
+type ProductResponse struct {
+	ProductID        string      `json:"productId"`
+	Name             string      `json:"name"`
+	Category         int         `json:"category"`
+	Qty              int         `json:"qty"`
+	ReservedQty      int         `json:"reservedQty"`
+	Price            money.Money `json:"price"`
+	Currency         string      `json:"currency"`
+	SKU              string      `json:"sku"`
+	FileID           string      `json:"fileId"`
+	FileURI          string      `json:"fileUri"`
+	FileThumbnailURI string      `json:"fileThumbnailUri"`
+	CreatedAt        string      `json:"createdAt"`
+	UpdatedAt        string      `json:"updatedAt"`
+}
+
+// CreateProductRequest caps qty and price at what the INT and NUMERIC(15, 2)
+// columns hold, larger values would only fail at the insert
+type CreateProductRequest struct {
+	Name     string      `json:"name" validate:"required,min=4,max=32"`
+	Category int         `json:"category" validate:"required,oneof=1 2 3 4 5"`
+	Qty      int         `json:"qty" validate:"required,min=1,max=2147483647"`
+	Price    money.Money `json:"price" validate:"required,min=100,max=9999999999999.99"`
+	SKU      string      `json:"sku" validate:"required,max=32"`
+	FileID   string      `json:"fileId" validate:"required,numeric"`
+}
+
+type UpdateProductRequest struct {
+	Name     string      `json:"name" validate:"required,min=4,max=32"`
+	Category int         `json:"category" validate:"required,oneof=1 2 3 4 5"`
+	Qty      int         `json:"qty" validate:"required,min=1,max=2147483647"`
+	Price    money.Money `json:"price" validate:"required,min=100,max=9999999999999.99"`
+	SKU      string      `json:"sku" validate:"required,max=32"`
+	FileID   string      `json:"fileId" validate:"required,numeric"`
+}
+
+type GetProductsQuery struct {
+	PaginationQuery
+	ProductID string      `query:"productId" validate:"omitempty,numeric"`
+	Category  int         `query:"category" validate:"omitempty,oneof=1 2 3 4 5"`
+	SKU       string      `query:"sku" validate:"omitempty,max=32"`
+	UserID    int         `query:"userId" validate:"omitempty,min=1"`
+	MinPrice  money.Money `query:"minPrice" validate:"omitempty,min=0"`
+	MaxPrice  money.Money `query:"maxPrice" validate:"omitempty,min=0,gtefield=MinPrice"`
+	InStock   bool        `query:"inStock"`
+	SortBy    string      `query:"sortBy" validate:"omitempty,oneof=newest oldest cheapest expensive"`
+}
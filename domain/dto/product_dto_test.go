@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+func TestCreateProductRequestBounds(t *testing.T) {
+	tests := []struct {
+		name    string
+		qty     int
+		price   money.Money
+		wantErr bool
+	}{
+		{name: "smallest price", qty: 1, price: money.New(10000, "IDR")},
+		{name: "largest price", qty: 1, price: money.New(999999999999999, "IDR")},
+		{name: "largest qty", qty: 2147483647, price: money.New(10000, "IDR")},
+		{name: "price below the minimum", qty: 1, price: money.New(9999, "IDR"), wantErr: true},
+		{name: "price above NUMERIC(15, 2)", qty: 1, price: money.New(1000000000000000, "IDR"), wantErr: true},
+		{name: "qty above INT", qty: 2147483648, price: money.New(10000, "IDR"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := CreateProductRequest{
+				Name:     "Product",
+				Category: 1,
+				Qty:      tt.qty,
+				Price:    tt.price,
+				SKU:      "SKU-1",
+				FileID:   "1",
+			}
+
+			err := validator.Validator.Validate(req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,48 +1,127 @@
 package dto
 
-import "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+import (
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
 
 // PurchaseItem represents an item in the purchase
 type PurchaseItem struct {
-	ProductID        int     `json:"productId"`
-	Name             string  `json:"name"`
-	Category         int     `json:"category"`
-	Quantity         int     `json:"qty"`
-	Price            float64 `json:"price"`
-	SKU              string  `json:"sku"`
-	FileID           string  `json:"fileId"`
-	FileURL          string  `json:"fileUri"`
-	FileThumbnailURL string  `json:"fileThumbnailUri"`
-	CreatedAt        string  `json:"createdAt"`
-	UpdatedAt        string  `json:"updatedAt"`
-}
-
-// PaymentDetail represents payment details for a seller
+	ProductID        int         `json:"productId"`
+	Name             string      `json:"name"`
+	Category         int         `json:"category"`
+	Quantity         int         `json:"qty"`
+	Price            money.Money `json:"price"`
+	SKU              string      `json:"sku"`
+	FileID           string      `json:"fileId"`
+	FileURL          string      `json:"fileUri"`
+	FileThumbnailURL string      `json:"fileThumbnailUri"`
+	CreatedAt        string      `json:"createdAt"`
+	UpdatedAt        string      `json:"updatedAt"`
+}
+
+// PaymentDetail represents payment details for a seller. TotalPrice is what
+// the buyer owes the seller, Subtotal less the voucher Discount.
 type PaymentDetail struct {
-	BankAccountName   string  `json:"bankAccountName"`
-	BankAccountHolder string  `json:"bankAccountHolder"`
-	BankAccountNumber string  `json:"bankAccountNumber"`
-	TotalPrice        float64 `json:"totalPrice"`
+	SellerID          string      `json:"sellerId"`
+	Status            string      `json:"status"`
+	BankAccountName   string      `json:"bankAccountName"`
+	BankAccountHolder string      `json:"bankAccountHolder"`
+	BankAccountNumber string      `json:"bankAccountNumber"`
+	Subtotal          money.Money `json:"subtotal"`
+	Discount          money.Money `json:"discount"`
+	VoucherCode       string      `json:"voucherCode,omitempty"`
+	TotalPrice        money.Money `json:"totalPrice"`
+	Note              string      `json:"note,omitempty"`
+	ShippedAt         string      `json:"shippedAt,omitempty"`
+	PaymentProofIDs   []string    `json:"paymentProofIds,omitempty"`
+}
+
+type PurchaseItemRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Qty       int    `json:"qty" validate:"required,min=2"`
 }
 
 type PurchaseRequest struct {
-	PurchasedItems []struct {
-		ProductID string `json:"product_id" validate:"required"`
-		Qty       int    `json:"qty" validate:"required,min=2"`
-	} `json:"purchased_items" validate:"required,min=1"`
-	SenderName          string `json:"sender_name" validate:"required,min=4,max=55"`
-	SenderContactType   string `json:"sender_contact_type" validate:"required,oneof=email phone"`
-	SenderContactDetail string `json:"sender_contact_detail" validate:"required"`
+	PurchasedItems      []PurchaseItemRequest `json:"purchased_items" validate:"required,min=1,dive"`
+	SenderName          string                `json:"sender_name" validate:"required,min=4,max=55"`
+	SenderContactType   string                `json:"sender_contact_type" validate:"required,oneof=email phone"`
+	SenderContactDetail string                `json:"sender_contact_detail" validate:"required"`
+	VoucherCode         string                `json:"voucher_code" validate:"omitempty,alphanum,max=32"`
 }
 
-// PurchaseResponse represents the response for a purchase
+// PurchaseResponse represents the response for a purchase. TotalPrice leaves
+// out the shares refunded by sellers, which add up to RefundedPrice.
+// LookupToken is only set at checkout, guests send it in the X-Lookup-Token
+// header to read and pay the purchase without logging in until it expires.
 type PurchaseResponse struct {
 	PurchaseID     string                `json:"purchaseId"`
+	Status         string                `json:"status"`
 	PurchasedItems []entity.PurchaseItem `json:"purchasedItems"`
-	TotalPrice     float64               `json:"totalPrice"`
+	TotalPrice     money.Money           `json:"totalPrice"`
+	RefundedPrice  money.Money           `json:"refundedPrice"`
+	Currency       string                `json:"currency"`
+	Discounts      []DiscountLine        `json:"discounts"`
 	PaymentDetails []PaymentDetail       `json:"paymentDetails"`
+	ExpiresAt      string                `json:"expiresAt"`
+	CreatedAt      string                `json:"createdAt"`
+	LookupToken    string                `json:"lookupToken,omitempty"`
+}
+
+// GetPurchasesQuery filters the purchase history of a buyer
+type GetPurchasesQuery struct {
+	PaginationQuery
+	Status string `query:"status" validate:"omitempty,oneof=pending paid confirmed shipped completed cancelled expired"`
 }
 
+// UploadPaymentRequest attaches payment proofs to a purchase. Without a
+// SellerID the proofs cover every seller that hasn't been paid yet.
 type UploadPaymentRequest struct {
-	FileIDs []string `json:"file_ids" validate:"required,min=1"`
+	FileIDs  []string `json:"file_ids" validate:"required,min=1,dive,numeric"`
+	SellerID string   `json:"seller_id" validate:"omitempty,numeric"`
+}
+
+type UpdatePurchaseStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=shipped"`
+	Note   string `json:"note" validate:"max=255"`
+}
+
+type PurchaseEventResponse struct {
+	FromStatus  string `json:"fromStatus"`
+	ToStatus    string `json:"toStatus"`
+	ActorUserID string `json:"actorUserId"`
+	Note        string `json:"note"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// GetSellerOrdersQuery filters the purchases that include products of the seller
+type GetSellerOrdersQuery struct {
+	PaginationQuery
+	Status        string `query:"status" validate:"omitempty,oneof=pending paid confirmed shipped completed cancelled expired"`
+	PaymentStatus string `query:"paymentStatus" validate:"omitempty,oneof=pending submitted confirmed rejected"`
+}
+
+// SellerOrderResponse is a purchase as seen by one of its sellers, limited to
+// the items and the payment share of that seller
+type SellerOrderResponse struct {
+	PurchaseID          string                `json:"purchaseId"`
+	Status              string                `json:"status"`
+	SenderName          string                `json:"senderName"`
+	SenderContactType   string                `json:"senderContactType"`
+	SenderContactDetail string                `json:"senderContactDetail"`
+	PurchasedItems      []entity.PurchaseItem `json:"purchasedItems"`
+	Payment             PaymentDetail         `json:"payment"`
+	CreatedAt           string                `json:"createdAt"`
+}
+
+type RejectPaymentRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type CancelPurchaseRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+type RefundPaymentRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
 }
@@ -9,6 +9,8 @@ type GetUserResponse struct {
 	BankAccountName   string `json:"bankAccountName"`
 	BankAccountHolder string `json:"bankAccountHolder"`
 	BankAccountNumber string `json:"bankAccountNumber"`
+	EmailVerified     bool   `json:"emailVerified"`
+	PhoneVerified     bool   `json:"phoneVerified"`
 }
 
 type UpdateUserRequest struct {
@@ -58,3 +60,22 @@ type LinkPhoneResponse struct {
 	BankAccountHolder string `json:"bankAccountHolder"`
 	BankAccountNumber string `json:"bankAccountNumber"`
 }
+
+// ChangePasswordRequest applies the same rules to the new password as the
+// register requests
+type ChangePasswordRequest struct {
+	CurrentPassword     string `json:"currentPassword" validate:"required"`
+	NewPassword         string `json:"newPassword" validate:"required,min=8,max=32"`
+	RevokeOtherSessions bool   `json:"revokeOtherSessions"`
+}
+
+// SessionResponse is a device the user is logged in on. LastUsedAt is when
+// its access token was last refreshed.
+type SessionResponse struct {
+	SessionID  string `json:"sessionId"`
+	UserAgent  string `json:"userAgent"`
+	IPAddress  string `json:"ipAddress"`
+	Current    bool   `json:"current"`
+	CreatedAt  string `json:"createdAt"`
+	LastUsedAt string `json:"lastUsedAt"`
+}
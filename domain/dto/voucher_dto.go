@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
+
+type VoucherResponse struct {
+	VoucherID    string      `json:"voucherId"`
+	Code         string      `json:"code"`
+	DiscountType string      `json:"discountType"`
+	PercentOff   int         `json:"percentOff"`
+	AmountOff    money.Money `json:"amountOff"`
+	MaxDiscount  money.Money `json:"maxDiscount"`
+	MinSpend     money.Money `json:"minSpend"`
+	Category     int         `json:"category"`
+	UsageLimit   int         `json:"usageLimit"`
+	PerUserLimit int         `json:"perUserLimit"`
+	UsedCount    int         `json:"usedCount"`
+	StartsAt     string      `json:"startsAt"`
+	EndsAt       string      `json:"endsAt"`
+	CreatedAt    string      `json:"createdAt"`
+}
+
+// CreateVoucherRequest creates a voucher for the items of the seller. A zero
+// Category, UsageLimit, PerUserLimit or MaxDiscount means no restriction.
+// Setting PerUserLimit keeps guests from redeeming the voucher.
+type CreateVoucherRequest struct {
+	Code         string      `json:"code" validate:"required,min=3,max=32,alphanum"`
+	DiscountType string      `json:"discountType" validate:"required,oneof=percentage fixed"`
+	PercentOff   int         `json:"percentOff" validate:"required_if=DiscountType percentage,min=0,max=100"`
+	AmountOff    money.Money `json:"amountOff" validate:"required_if=DiscountType fixed,min=0"`
+	MaxDiscount  money.Money `json:"maxDiscount" validate:"min=0"`
+	MinSpend     money.Money `json:"minSpend" validate:"min=0"`
+	Category     int         `json:"category" validate:"omitempty,oneof=1 2 3 4 5"`
+	UsageLimit   int         `json:"usageLimit" validate:"omitempty,min=1"`
+	PerUserLimit int         `json:"perUserLimit" validate:"omitempty,min=1"`
+	StartsAt     time.Time   `json:"startsAt" validate:"required"`
+	EndsAt       time.Time   `json:"endsAt" validate:"required,gtfield=StartsAt"`
+}
+
+// RedeemVoucherRequest applies a voucher to a purchase being created.
+// BuyerID is 0 for guests, who can't redeem vouchers with a PerUserLimit.
+type RedeemVoucherRequest struct {
+	Code         string
+	PurchaseID   int
+	BuyerID      int
+	BuyerContact string
+	Items        []entity.PurchaseItem
+}
+
+// DiscountLine is the discount a voucher gives on the share of one seller
+type DiscountLine struct {
+	VoucherCode string      `json:"voucherCode"`
+	SellerID    string      `json:"sellerId"`
+	Amount      money.Money `json:"amount"`
+}
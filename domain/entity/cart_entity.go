@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
+
+// Cart represents the "carts" table. Guest carts have no UserID and are
+// reached through a signed cart token instead.
+type Cart struct {
+	ID        int           `db:"id"`
+	UserID    sql.NullInt32 `db:"user_id"`
+	CreatedAt time.Time     `db:"created_at"`
+	UpdatedAt time.Time     `db:"updated_at"`
+}
+
+// CartItem represents the "cart_items" table. Price is the price of the
+// product when it was put in the cart.
+type CartItem struct {
+	ID        int         `db:"id"`
+	CartID    int         `db:"cart_id"`
+	ProductID int         `db:"product_id"`
+	Quantity  int         `db:"qty"`
+	Price     money.Money `db:"price"`
+	CreatedAt time.Time   `db:"created_at"`
+	UpdatedAt time.Time   `db:"updated_at"`
+}
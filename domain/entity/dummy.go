@@ -17,19 +17,3 @@ type DummyUser struct {
 	BankAccountNumber string    `db:"bank_account_number"`
 	CreatedAt         time.Time `db:"created_at"`
 }
-
-// Product represents the "products" table
-type Product struct {
-	ID               int       `db:"id"`
-	Name             string    `db:"name"`
-	Category         int       `db:"category"`
-	Quantity         int       `db:"qty"`
-	Price            float64   `db:"price"`
-	SKU              string    `db:"sku"`
-	FileID           string    `db:"file_id"`
-	FileURL          string    `db:"file_url"`
-	FileThumbnailURL string    `db:"file_thumbnail_url"`
-	UserID           int       `db:"user_id"`
-	CreatedAt        time.Time `db:"created_at"`
-	UpdatedAt        time.Time `db:"updated_at"`
-}
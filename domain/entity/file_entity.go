@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// File represents the "files" table
+type File struct {
+	ID               int           `db:"id"`
+	UserID           sql.NullInt32 `db:"user_id"`
+	PurchaseID       sql.NullInt32 `db:"purchase_id"`
+	FileURI          string        `db:"file_uri"`
+	FileThumbnailURI string        `db:"file_thumbnail_uri"`
+	CreatedAt        time.Time     `db:"created_at"`
+}
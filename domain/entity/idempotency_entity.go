@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// IdempotencyKey represents the "idempotency_keys" table. A key is claimed
+// before the request runs and completed with the response it produced.
+type IdempotencyKey struct {
+	Key          string        `db:"idempotency_key"`
+	Scope        string        `db:"scope"`
+	RequestHash  string        `db:"request_hash"`
+	StatusCode   sql.NullInt32 `db:"status_code"`
+	ContentType  string        `db:"content_type"`
+	ResponseBody []byte        `db:"response_body"`
+	CreatedAt    time.Time     `db:"created_at"`
+	CompletedAt  sql.NullTime  `db:"completed_at"`
+}
@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// One-time code purposes
+const (
+	OneTimeCodePasswordReset = "password_reset"
+	OneTimeCodeVerifyEmail   = "verify_email"
+	OneTimeCodeVerifyPhone   = "verify_phone"
+)
+
+// OneTimeCode represents the "one_time_codes" table. A code is sent to Target,
+// the email address or phone number of the user, and only its bcrypt hash is
+// stored. UsedAt is set once it has been used or replaced by a newer code.
+type OneTimeCode struct {
+	ID        int          `db:"id"`
+	UserID    int          `db:"user_id"`
+	Purpose   string       `db:"purpose"`
+	Target    string       `db:"target"`
+	CodeHash  string       `db:"code_hash"`
+	Attempts  int          `db:"attempts"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
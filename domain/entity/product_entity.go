@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
+
+// Product categories
+const (
+	ProductCategoryFood = iota + 1
+	ProductCategoryBeverage
+	ProductCategoryClothes
+	ProductCategoryFurniture
+	ProductCategoryTools
+)
+
+// Product represents the "products" table
+type Product struct {
+	ID               int         `db:"id"`
+	Name             string      `db:"name"`
+	Category         int         `db:"category"`
+	Quantity         int         `db:"qty"`
+	ReservedQuantity int         `db:"reserved_qty"`
+	Price            money.Money `db:"price"`
+	SKU              string      `db:"sku"`
+	FileID           string      `db:"file_id"`
+	FileURL          string      `db:"file_url"`
+	FileThumbnailURL string      `db:"file_thumbnail_url"`
+	UserID           int         `db:"user_id"`
+	CreatedAt        time.Time   `db:"created_at"`
+	UpdatedAt        time.Time   `db:"updated_at"`
+}
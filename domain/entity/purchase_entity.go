@@ -1,28 +1,132 @@
 package entity
 
-import "time"
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
+
+// Purchase statuses
+const (
+	PurchaseStatusPending   = "pending"
+	PurchaseStatusPaid      = "paid"
+	PurchaseStatusConfirmed = "confirmed"
+	PurchaseStatusShipped   = "shipped"
+	PurchaseStatusCompleted = "completed"
+	PurchaseStatusCancelled = "cancelled"
+	PurchaseStatusExpired   = "expired"
+)
+
+// Purchase payment statuses
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusSubmitted = "submitted"
+	PaymentStatusConfirmed = "confirmed"
+	PaymentStatusRejected  = "rejected"
+	PaymentStatusRefunded  = "refunded"
+)
 
 // Purchase represents the "purchase" table
 type Purchase struct {
-	ID                  int            `db:"id"`
-	PurchasedItems      []PurchaseItem `db:"purchased_items"`
-	SenderName          string         `db:"sender_name"`
-	SenderContactType   string         `db:"sender_contact_type"`
-	SenderContactDetail string         `db:"sender_contact_detail"`
-	PaymentProofIDs     []string       `db:"payment_proof_ids"`
-	CreatedAt           time.Time      `db:"created_at"`
-	UpdatedAt           time.Time      `db:"updated_at"`
+	ID                  int           `db:"id"`
+	BuyerID             sql.NullInt32 `db:"buyer_id"`
+	PurchasedItems      PurchaseItems `db:"purchased_items"`
+	SenderName          string        `db:"sender_name"`
+	SenderContactType   string        `db:"sender_contact_type"`
+	SenderContactDetail string        `db:"sender_contact_detail"`
+	Status              string        `db:"status"`
+	ExpiresAt           sql.NullTime  `db:"expires_at"`
+	CreatedAt           time.Time     `db:"created_at"`
+	UpdatedAt           time.Time     `db:"updated_at"`
 }
 
-// PurchaseItem represents an item in the "purchased_items" JSONB array in the "purchase" table
+// PurchaseItem represents an item in the "purchased_items" JSONB array in the
+// "purchase" table, and a row of the "purchase_items" table
 type PurchaseItem struct {
-	ProductID        int     `db:"product_id"`
-	Name             string  `db:"name"`
-	Category         int     `db:"category"`
-	Quantity         int     `db:"qty"`
-	Price            float64 `db:"price"`
-	SKU              string  `db:"sku"`
-	FileID           string  `db:"file_id"`
-	FileURL          string  `db:"file_url"`
-	FileThumbnailURL string  `db:"file_thumbnail_url"`
+	PurchaseID       int         `db:"purchase_id" json:"-"`
+	ProductID        int         `db:"product_id" json:"productId"`
+	SellerID         int         `db:"seller_id" json:"sellerId"`
+	Name             string      `db:"name" json:"name"`
+	Category         int         `db:"category" json:"category"`
+	Quantity         int         `db:"qty" json:"qty"`
+	Price            money.Money `db:"price" json:"price"`
+	SKU              string      `db:"sku" json:"sku"`
+	FileID           string      `db:"file_id" json:"fileId"`
+	FileURL          string      `db:"file_url" json:"fileUri"`
+	FileThumbnailURL string      `db:"file_thumbnail_url" json:"fileThumbnailUri"`
+}
+
+// PurchasePayment represents the "purchase_payments" table, the share of a
+// purchase that is paid to a single seller
+type PurchasePayment struct {
+	ID                int           `db:"id"`
+	PurchaseID        int           `db:"purchase_id"`
+	SellerID          int           `db:"seller_id"`
+	BankAccountName   string        `db:"bank_account_name"`
+	BankAccountHolder string        `db:"bank_account_holder"`
+	BankAccountNumber string        `db:"bank_account_number"`
+	Subtotal          money.Money   `db:"subtotal"`
+	Discount          money.Money   `db:"discount"`
+	VoucherCode       string        `db:"voucher_code"`
+	TotalPrice        money.Money   `db:"total_price"`
+	Status            string        `db:"status"`
+	Note              string        `db:"note"`
+	RefundedAt        sql.NullTime  `db:"refunded_at"`
+	RefundedBy        sql.NullInt32 `db:"refunded_by"`
+	ShippedAt         sql.NullTime  `db:"shipped_at"`
+	CreatedAt         time.Time     `db:"created_at"`
+	UpdatedAt         time.Time     `db:"updated_at"`
+}
+
+// PurchasePaymentProof represents the "purchase_payment_proofs" table
+type PurchasePaymentProof struct {
+	ID                int       `db:"id"`
+	PurchasePaymentID int       `db:"purchase_payment_id"`
+	FileID            int       `db:"file_id"`
+	CreatedAt         time.Time `db:"created_at"`
+}
+
+// PurchaseEvent represents the "purchase_events" table, the status history of a purchase
+type PurchaseEvent struct {
+	ID          int            `db:"id"`
+	PurchaseID  int            `db:"purchase_id"`
+	FromStatus  sql.NullString `db:"from_status"`
+	ToStatus    string         `db:"to_status"`
+	ActorUserID sql.NullInt32  `db:"actor_user_id"`
+	Note        string         `db:"note"`
+	CreatedAt   time.Time      `db:"created_at"`
+}
+
+// PurchaseItems is stored as a JSONB array
+type PurchaseItems []PurchaseItem
+
+func (p PurchaseItems) Value() (driver.Value, error) {
+	if p == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+func (p *PurchaseItems) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*p = PurchaseItems{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	}
+
+	return errors.New("unsupported type for PurchaseItems")
 }
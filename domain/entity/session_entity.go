@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Reasons a session is revoked for
+const (
+	SessionRevokedLogout          = "logout"
+	SessionRevokedTokenReused     = "refresh token reused"
+	SessionRevokedByUser          = "revoked by user"
+	SessionRevokedPasswordReset   = "password reset"
+	SessionRevokedPasswordChanged = "password changed"
+)
+
+// Session represents the "sessions" table. A session starts at login and
+// holds the family of refresh tokens rotated from the first one. Its ID is
+// the jti of every access token issued for it. IPAddress is where it was
+// last refreshed from.
+type Session struct {
+	ID            string       `db:"id"`
+	UserID        int          `db:"user_id"`
+	UserAgent     string       `db:"user_agent"`
+	IPAddress     string       `db:"ip_address"`
+	CreatedAt     time.Time    `db:"created_at"`
+	LastUsedAt    time.Time    `db:"last_used_at"`
+	RevokedAt     sql.NullTime `db:"revoked_at"`
+	RevokedReason string       `db:"revoked_reason"`
+}
+
+// RefreshToken represents the "refresh_tokens" table. Only the SHA-256 hash of
+// the token is stored, UsedAt is set once it has been rotated.
+type RefreshToken struct {
+	ID        int          `db:"id"`
+	SessionID string       `db:"session_id"`
+	TokenHash string       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
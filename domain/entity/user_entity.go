@@ -10,8 +10,10 @@ type User struct {
 	BankAccountName   sql.NullString `db:"bank_account_name" json:"bankAccoutnName"`
 	BankAccountHolder sql.NullString `db:"bank_account_holder" json:"bankAccountHolder"`
 	BankAccountNumber sql.NullString `db:"bank_account_number" json:"bankAccountNumber"`
-	FileID            sql.NullInt16  `db:"file_id" json:"fileId"`
+	FileID            sql.NullInt32  `db:"file_id" json:"fileId"`
 	FileURI           sql.NullString `db:"file_uri" json:"fileUri"`
 	FileThumbnailURI  sql.NullString `db:"file_thumbnail_uri" json:"fileThumbnailUri"`
 	CreatedAt         string         `db:"created_at" json:"createdAt"`
+	EmailVerifiedAt   sql.NullTime   `db:"email_verified_at" json:"-"`
+	PhoneVerifiedAt   sql.NullTime   `db:"phone_verified_at" json:"-"`
 }
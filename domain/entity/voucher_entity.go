@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
+
+// Voucher discount types
+const (
+	VoucherDiscountPercentage = "percentage"
+	VoucherDiscountFixed      = "fixed"
+)
+
+// Voucher represents the "vouchers" table. A voucher only discounts the items
+// of the seller who created it, optionally only those of one category.
+type Voucher struct {
+	ID           int           `db:"id"`
+	Code         string        `db:"code"`
+	SellerID     int           `db:"seller_id"`
+	DiscountType string        `db:"discount_type"`
+	PercentOff   int           `db:"percent_off"`
+	AmountOff    money.Money   `db:"amount_off"`
+	MaxDiscount  money.Money   `db:"max_discount"`
+	MinSpend     money.Money   `db:"min_spend"`
+	Category     sql.NullInt32 `db:"category"`
+	UsageLimit   sql.NullInt32 `db:"usage_limit"`
+	PerUserLimit sql.NullInt32 `db:"per_user_limit"`
+	UsedCount    int           `db:"used_count"`
+	StartsAt     time.Time     `db:"starts_at"`
+	EndsAt       time.Time     `db:"ends_at"`
+	CreatedAt    time.Time     `db:"created_at"`
+	UpdatedAt    time.Time     `db:"updated_at"`
+}
+
+// VoucherRedemption represents the "voucher_redemptions" table
+type VoucherRedemption struct {
+	ID           int           `db:"id"`
+	VoucherID    int           `db:"voucher_id"`
+	PurchaseID   int           `db:"purchase_id"`
+	BuyerID      sql.NullInt32 `db:"buyer_id"`
+	BuyerContact string        `db:"buyer_contact"`
+	Discount     money.Money   `db:"discount"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
@@ -18,6 +18,13 @@ func (r *RequestError) Error() string {
 	return r.Err.Error()
 }
 
+// IsRequestError reports whether err already carries the status it should be
+// answered with, e.g. a constraint violation mapped by a repository
+func IsRequestError(err error) bool {
+	var requestErr *RequestError
+	return errors.As(err, &requestErr)
+}
+
 var ErrNotFound = &RequestError{
 	StatusCode: http.StatusNotFound,
 	Err:        errors.New("something not found"),
@@ -63,6 +70,11 @@ var ErrBearerTokenNotActive = &RequestError{
 	Err:        errors.New("bearer token not active"),
 }
 
+var ErrRevokedBearerToken = &RequestError{
+	StatusCode: http.StatusUnauthorized,
+	Err:        errors.New("bearer token has been revoked"),
+}
+
 var ErrEmailNotFound = &RequestError{
 	StatusCode: http.StatusNotFound,
 	Err:        errors.New("email not found"),
@@ -93,11 +105,31 @@ var ErrFileNotFound = &RequestError{
 	Err:        errors.New("file not found"),
 }
 
+var ErrFileNotOwned = &RequestError{
+	StatusCode: http.StatusForbidden,
+	Err:        errors.New("file does not belong to user"),
+}
+
+var ErrFileNotOfPurchase = &RequestError{
+	StatusCode: http.StatusForbidden,
+	Err:        errors.New("file was not uploaded for the purchase"),
+}
+
 var ErrInvalidMimeType = &RequestError{
 	StatusCode: http.StatusBadRequest,
 	Err:        errors.New("invalid mime type"),
 }
 
+var ErrInsufficientStock = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("insufficient product stock"),
+}
+
+var ErrReservationMismatch = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("reserved product stock does not cover the purchase"),
+}
+
 var ErrEntityNotFound = &RequestError{
 	StatusCode: http.StatusNotFound,
 	Err:        errors.New("entity not found"),
@@ -107,3 +139,153 @@ var ErrMultipleEntities = &RequestError{
 	StatusCode: http.StatusInternalServerError,
 	Err:        errors.New("multiple entities found"),
 }
+
+var ErrInvalidIdempotencyKey = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("invalid idempotency key"),
+}
+
+var ErrIdempotencyKeyReused = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("idempotency key was already used for a different request"),
+}
+
+var ErrIdempotencyKeyInProgress = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("a request with this idempotency key is still being processed"),
+}
+
+var ErrVoucherNotFound = &RequestError{
+	StatusCode: http.StatusNotFound,
+	Err:        errors.New("voucher not found"),
+}
+
+var ErrVoucherNotOwned = &RequestError{
+	StatusCode: http.StatusForbidden,
+	Err:        errors.New("voucher does not belong to user"),
+}
+
+var ErrVoucherCodeAlreadyExists = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("voucher code already exists"),
+}
+
+var ErrVoucherNotActive = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("voucher is not active"),
+}
+
+var ErrVoucherNotApplicable = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("voucher does not apply to any purchased item"),
+}
+
+var ErrVoucherMinSpendNotMet = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("voucher minimum spend not met"),
+}
+
+var ErrVoucherUsageLimitReached = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("voucher usage limit reached"),
+}
+
+var ErrVoucherLoginRequired = &RequestError{
+	StatusCode: http.StatusUnauthorized,
+	Err:        errors.New("voucher can only be redeemed by logged in buyers"),
+}
+
+var ErrInvalidCartToken = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("invalid cart token"),
+}
+
+var ErrCartEmpty = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("cart is empty"),
+}
+
+var ErrCartItemNotFound = &RequestError{
+	StatusCode: http.StatusNotFound,
+	Err:        errors.New("product is not in the cart"),
+}
+
+var ErrCartOutOfStock = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("some items in the cart are out of stock"),
+}
+
+var ErrCartPriceChanged = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("prices in the cart have changed, review the cart before checking out"),
+}
+
+var ErrInvalidRefreshToken = &RequestError{
+	StatusCode: http.StatusUnauthorized,
+	Err:        errors.New("invalid refresh token"),
+}
+
+var ErrRefreshTokenReused = &RequestError{
+	StatusCode: http.StatusUnauthorized,
+	Err:        errors.New("refresh token was already used, the session has been revoked"),
+}
+
+var ErrSessionNotFound = &RequestError{
+	StatusCode: http.StatusNotFound,
+	Err:        errors.New("session not found"),
+}
+
+var ErrInvalidOneTimeCode = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("invalid or expired code"),
+}
+
+var ErrOneTimeCodeTooSoon = &RequestError{
+	StatusCode: http.StatusTooManyRequests,
+	Err:        errors.New("a code was sent recently, try again later"),
+}
+
+var ErrEmailNotVerified = &RequestError{
+	StatusCode: http.StatusForbidden,
+	Err:        errors.New("email is not verified"),
+}
+
+var ErrPhoneNotVerified = &RequestError{
+	StatusCode: http.StatusForbidden,
+	Err:        errors.New("phone is not verified"),
+}
+
+var ErrUserPhoneAlreadyExists = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("user phone already exists"),
+}
+
+var ErrProductNotFound = &RequestError{
+	StatusCode: http.StatusNotFound,
+	Err:        errors.New("product not found"),
+}
+
+var ErrAlreadyExists = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("resource already exists"),
+}
+
+var ErrReferenceNotFound = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("referenced resource not found"),
+}
+
+var ErrStillReferenced = &RequestError{
+	StatusCode: http.StatusConflict,
+	Err:        errors.New("resource is still in use"),
+}
+
+var ErrInvalidCurrentPassword = &RequestError{
+	StatusCode: http.StatusBadRequest,
+	Err:        errors.New("current password is incorrect"),
+}
+
+var ErrTooManyLoginAttempts = &RequestError{
+	StatusCode: http.StatusTooManyRequests,
+	Err:        errors.New("too many failed login attempts, try again later"),
+}
@@ -4,13 +4,15 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
 )
 
 type authController struct {
 	service contracts.AuthService
 }
 
-func InitAuthController(router fiber.Router, service contracts.AuthService) {
+func InitAuthController(router fiber.Router, service contracts.AuthService, middleware *middlewares.Middleware) {
 	controller := &authController{
 		service,
 	}
@@ -19,6 +21,12 @@ func InitAuthController(router fiber.Router, service contracts.AuthService) {
 	router.Post("/login/phone", controller.loginWithPhone)
 	router.Post("/register/email", controller.registerWithEmail)
 	router.Post("/register/phone", controller.registerWithPhone)
+	router.Post("/auth/refresh", controller.refresh)
+	router.Post("/auth/logout", middleware.RequireAuth(), controller.logout)
+	router.Post("/auth/password/forgot", controller.forgotPassword)
+	router.Post("/auth/password/reset", controller.resetPassword)
+	router.Post("/auth/verify/send", controller.sendVerification)
+	router.Post("/auth/verify", controller.verifyContact)
 }
 
 func (c *authController) loginWithEmail(ctx *fiber.Ctx) error {
@@ -27,7 +35,7 @@ func (c *authController) loginWithEmail(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	res, err := c.service.LoginWithEmail(ctx.Context(), &req)
+	res, err := c.service.LoginWithEmail(ctx.Context(), &req, clientInfo(ctx))
 	if err != nil {
 		return err
 	}
@@ -41,7 +49,7 @@ func (c *authController) loginWithPhone(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	res, err := c.service.LoginWithPhone(ctx.Context(), &req)
+	res, err := c.service.LoginWithPhone(ctx.Context(), &req, clientInfo(ctx))
 	if err != nil {
 		return err
 	}
@@ -76,3 +84,99 @@ func (c *authController) registerWithPhone(ctx *fiber.Ctx) error {
 
 	return ctx.Status(fiber.StatusCreated).JSON(res)
 }
+
+func (c *authController) refresh(ctx *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.Refresh(ctx.Context(), &req, clientInfo(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *authController) logout(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(jwt.Claims)
+
+	err := c.service.Logout(ctx.Context(), claims.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (c *authController) forgotPassword(ctx *fiber.Ctx) error {
+	var req dto.ForgotPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := c.service.ForgotPassword(ctx.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (c *authController) resetPassword(ctx *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := c.service.ResetPassword(ctx.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (c *authController) sendVerification(ctx *fiber.Ctx) error {
+	var req dto.SendVerificationRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := c.service.SendVerification(ctx.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (c *authController) verifyContact(ctx *fiber.Ctx) error {
+	var req dto.VerifyContactRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := c.service.VerifyContact(ctx.Context(), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+// maxUserAgentLength is the size of the sessions.user_agent column
+const maxUserAgentLength = 512
+
+func clientInfo(ctx *fiber.Ctx) dto.ClientInfo {
+	userAgent := ctx.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	return dto.ClientInfo{
+		UserAgent: userAgent,
+		IPAddress: ctx.IP(),
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
 )
 
 type authRepository struct {
@@ -19,7 +20,7 @@ func NewAuthRepository(db *sqlx.DB) contracts.AuthRepository {
 // FindByEmail is a method to find a user by email
 func (r *authRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.GetContext(ctx, &user, "SELECT * FROM users WHERE email = ?", email)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &user, "SELECT * FROM users WHERE email = $1", email)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +31,7 @@ func (r *authRepository) FindByEmail(ctx context.Context, email string) (*entity
 // FindByPhone is a method to find a user by phone
 func (r *authRepository) FindByPhone(ctx context.Context, phone string) (*entity.User, error) {
 	var user entity.User
-	err := r.db.GetContext(ctx, &user, "SELECT * FROM users WHERE phone = ?", phone)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &user, "SELECT * FROM users WHERE phone = $1", phone)
 	if err != nil {
 		return nil, err
 	}
@@ -40,20 +41,42 @@ func (r *authRepository) FindByPhone(ctx context.Context, phone string) (*entity
 
 // RegisterWithEmail is a method to register a user with email
 func (r *authRepository) RegisterWithEmail(ctx context.Context, user *entity.User) error {
-	_, err := r.db.NamedExecContext(ctx, "INSERT INTO users (email, password) VALUES (:email, :password)", user)
-	if err != nil {
-		return err
-	}
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx,
+		"INSERT INTO users (email, password) VALUES ($1, $2) RETURNING id",
+		user.Email, user.Password,
+	).Scan(&user.ID)
+	return database.MapError(err)
+}
+
+// UpdatePassword is a method to replace the password hash of a user
+func (r *authRepository) UpdatePassword(ctx context.Context, userID int, password string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE users SET password = $1 WHERE id = $2", password, userID)
+	return err
+}
 
-	return nil
+// MarkEmailVerified is a method to mark the email of a user as verified
+func (r *authRepository) MarkEmailVerified(ctx context.Context, userID int, email string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE users SET email_verified_at = CURRENT_TIMESTAMP WHERE id = $1 AND email = $2",
+		userID, email,
+	)
+	return err
+}
+
+// MarkPhoneVerified is a method to mark the phone of a user as verified
+func (r *authRepository) MarkPhoneVerified(ctx context.Context, userID int, phone string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE users SET phone_verified_at = CURRENT_TIMESTAMP WHERE id = $1 AND phone = $2",
+		userID, phone,
+	)
+	return err
 }
 
 // RegisterWithPhone is a method to register a user with phone
 func (r *authRepository) RegisterWithPhone(ctx context.Context, user *entity.User) error {
-	_, err := r.db.NamedExecContext(ctx, "INSERT INTO users (phone, password) VALUES (:phone, :password)", user)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx,
+		"INSERT INTO users (phone, password) VALUES ($1, $2) RETURNING id",
+		user.Phone, user.Password,
+	).Scan(&user.ID)
+	return database.MapError(err)
 }
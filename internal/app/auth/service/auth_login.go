@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+// dummyPasswordHash is compared against when no user matches, so unknown
+// emails and phones take as long to reject as wrong passwords
+const dummyPasswordHash = "$2a$10$4jTetH83SfD/UYSWTXE55uF9pLwO54x/6MPYiyTsHY4ehdTpMJuda"
+
+// authenticate checks the password of the user returned by find. Unknown
+// users and wrong passwords fail alike, and every failure counts against
+// both the identifier and the IP address until they are locked out.
+func (s *authService) authenticate(
+	ctx context.Context,
+	identifier string,
+	ipAddress string,
+	password string,
+	find func(ctx context.Context) (*entity.User, error),
+) (*entity.User, error) {
+	identifierWait, err := s.identifierLimiter.Blocked(ctx, identifier)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	ipWait, err := s.ipLimiter.Blocked(ctx, ipAddress)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if identifierWait > 0 || ipWait > 0 {
+		return nil, domain.ErrTooManyLoginAttempts
+	}
+
+	user, err := find(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	hashedPassword := dummyPasswordHash
+	if user != nil {
+		hashedPassword = user.Password
+	}
+
+	if !s.bcrypt.Compare(password, hashedPassword) || user == nil {
+		err := s.loginFailed(ctx, identifier, ipAddress)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return nil, domain.ErrCredentialsNotMatch
+	}
+
+	// The IP address isn't reset, or logging in to an account of one's own
+	// would clear the failures made guessing at others
+	err = s.identifierLimiter.Reset(ctx, identifier)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return user, nil
+}
+
+func (s *authService) loginFailed(ctx context.Context, identifier string, ipAddress string) error {
+	identifierLockout, err := s.identifierLimiter.Fail(ctx, identifier)
+	if err != nil {
+		return err
+	}
+
+	ipLockout, err := s.ipLimiter.Fail(ctx, ipAddress)
+	if err != nil {
+		return err
+	}
+
+	if identifierLockout > 0 || ipLockout > 0 {
+		log.Warn(log.LogInfo{
+			"identifier":        identifier,
+			"ipAddress":         ipAddress,
+			"identifierLockout": identifierLockout.String(),
+			"ipLockout":         ipLockout.String(),
+		}, "[AuthService][loginFailed] login locked out")
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// ForgotPassword implements contracts.AuthService.
+func (s *authService) ForgotPassword(ctx context.Context, req *dto.ForgotPasswordRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	user, channel, target, err := s.findByContact(ctx, req.Email, req.Phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	// Whoever can receive codes on an unverified contact may not own it
+	if !contactVerified(user, channel) {
+		return nil
+	}
+
+	err = s.otpService.Issue(ctx, user.ID, entity.OneTimeCodePasswordReset, channel, target)
+	if err != nil {
+		// Unknown contacts can't be rate limited, so a known one mustn't
+		// answer differently either
+		if errors.Is(err, domain.ErrOneTimeCodeTooSoon) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword implements contracts.AuthService.
+func (s *authService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	user, channel, target, err := s.findByContact(ctx, req.Email, req.Phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidOneTimeCode
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if !contactVerified(user, channel) {
+		return domain.ErrInvalidOneTimeCode
+	}
+
+	err = s.otpService.Verify(ctx, user.ID, entity.OneTimeCodePasswordReset, target, req.Code)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.bcrypt.Hash(req.Password)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		err := s.repo.UpdatePassword(ctx, user.ID, hashedPassword)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		err = s.sessionRepo.RevokeByUserID(ctx, user.ID, "", entity.SessionRevokedPasswordReset)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return nil
+	})
+}
+
+// findByContact finds the user by email when it is given and by phone
+// otherwise, along with the channel and address to reach them on
+func (s *authService) findByContact(ctx context.Context, email string, phone string) (*entity.User, string, string, error) {
+	if email != "" {
+		user, err := s.repo.FindByEmail(ctx, email)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		return user, dto.NotificationChannelEmail, email, nil
+	}
+
+	user, err := s.repo.FindByPhone(ctx, phone)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return user, dto.NotificationChannelPhone, phone, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
@@ -15,45 +17,65 @@ import (
 )
 
 type authService struct {
-	repo      contracts.AuthRepository
-	validator validator.ValidatorInterface
-	bcrypt    bcrypt.BcryptInterface
-	jwt       jwt.JwtInterface
+	repo              contracts.AuthRepository
+	sessionRepo       contracts.SessionRepository
+	otpService        contracts.OneTimeCodeService
+	identifierLimiter contracts.LoginLimiter
+	ipLimiter         contracts.LoginLimiter
+	uow               contracts.UnitOfWork
+	validator         validator.ValidatorInterface
+	bcrypt            bcrypt.BcryptInterface
+	jwt               jwt.JwtInterface
+	refreshTokenTTL   time.Duration
 }
 
-func NewAuthService(repo contracts.AuthRepository, validator validator.ValidatorInterface, bcrypt bcrypt.BcryptInterface, jwt jwt.JwtInterface) contracts.AuthService {
+func NewAuthService(
+	repo contracts.AuthRepository,
+	sessionRepo contracts.SessionRepository,
+	otpService contracts.OneTimeCodeService,
+	identifierLimiter contracts.LoginLimiter,
+	ipLimiter contracts.LoginLimiter,
+	uow contracts.UnitOfWork,
+	validator validator.ValidatorInterface,
+	bcrypt bcrypt.BcryptInterface,
+	jwt jwt.JwtInterface,
+	refreshTokenTTL time.Duration,
+) contracts.AuthService {
 	return &authService{
 		repo,
+		sessionRepo,
+		otpService,
+		identifierLimiter,
+		ipLimiter,
+		uow,
 		validator,
 		bcrypt,
 		jwt,
+		refreshTokenTTL,
 	}
 }
 
 // LoginWithEmail is a method to login with email
-func (s *authService) LoginWithEmail(ctx context.Context, req *dto.LoginWithEmailRequest) (*dto.LoginWithEmailResponse, error) {
+func (s *authService) LoginWithEmail(ctx context.Context, req *dto.LoginWithEmailRequest, client dto.ClientInfo) (*dto.LoginWithEmailResponse, error) {
 	valErr := s.validator.Validate(req)
 	if valErr != nil {
 		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
 	}
 
-	user, err := s.repo.FindByEmail(ctx, req.Email)
+	user, err := s.authenticate(ctx, "email:"+req.Email, client.IPAddress, req.Password, func(ctx context.Context) (*entity.User, error) {
+		return s.repo.FindByEmail(ctx, req.Email)
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fiber.NewError(fiber.StatusNotFound, "email not found")
-		}
-
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return nil, err
 	}
 
-	isCorrect := s.bcrypt.Compare(req.Password, user.Password)
-	if !isCorrect {
-		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid email or password")
+	if !user.EmailVerifiedAt.Valid {
+		return nil, domain.ErrEmailNotVerified
 	}
 
-	token, err := s.jwt.Create(user.ID)
+	token, refreshToken, err := s.issueTokens(ctx, user.ID, client)
 	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return nil, err
 	}
 
 	email := ""
@@ -67,38 +89,36 @@ func (s *authService) LoginWithEmail(ctx context.Context, req *dto.LoginWithEmai
 	}
 
 	res := &dto.LoginWithEmailResponse{
-		Email: email,
-		Phone: phone,
-		Token: token,
+		Email:        email,
+		Phone:        phone,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}
 
 	return res, nil
 }
 
 // LoginWithPhone is a method to login with phone
-func (s *authService) LoginWithPhone(ctx context.Context, req *dto.LoginWithPhoneRequest) (*dto.LoginWithPhoneResponse, error) {
+func (s *authService) LoginWithPhone(ctx context.Context, req *dto.LoginWithPhoneRequest, client dto.ClientInfo) (*dto.LoginWithPhoneResponse, error) {
 	valErr := s.validator.Validate(req)
 	if valErr != nil {
 		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
 	}
 
-	user, err := s.repo.FindByPhone(ctx, req.Phone)
+	user, err := s.authenticate(ctx, "phone:"+req.Phone, client.IPAddress, req.Password, func(ctx context.Context) (*entity.User, error) {
+		return s.repo.FindByPhone(ctx, req.Phone)
+	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fiber.NewError(fiber.StatusNotFound, "phone not found")
-		}
-
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return nil, err
 	}
 
-	isCorrect := s.bcrypt.Compare(req.Password, user.Password)
-	if !isCorrect {
-		return nil, fiber.NewError(fiber.StatusUnauthorized, "invalid phone or password")
+	if !user.PhoneVerifiedAt.Valid {
+		return nil, domain.ErrPhoneNotVerified
 	}
 
-	token, err := s.jwt.Create(user.ID)
+	token, refreshToken, err := s.issueTokens(ctx, user.ID, client)
 	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return nil, err
 	}
 
 	email := ""
@@ -112,28 +132,37 @@ func (s *authService) LoginWithPhone(ctx context.Context, req *dto.LoginWithPhon
 	}
 
 	res := &dto.LoginWithPhoneResponse{
-		Email: email,
-		Phone: phone,
-		Token: token,
+		Email:        email,
+		Phone:        phone,
+		Token:        token,
+		RefreshToken: refreshToken,
 	}
 
 	return res, nil
 }
 
-// RegisterWithEmail is a method to register with email
+// RegisterWithEmail is a method to register with email. The user logs in once
+// the email is verified, a session before that would bypass the verification.
 func (s *authService) RegisterWithEmail(ctx context.Context, req *dto.RegisterWithEmailRequest) (*dto.RegisterWithEmailResponse, error) {
 	valErr := s.validator.Validate(req)
 	if valErr != nil {
 		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
 	}
 
-	_, err := s.repo.FindByEmail(ctx, req.Email)
+	// An unverified holder whose code ran out doesn't keep the email from its
+	// actual owner
+	err := s.otpService.ReleaseUnverified(ctx, entity.OneTimeCodeVerifyEmail, req.Email)
 	if err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
-			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
-		}
+		return nil, err
+	}
 
-		return nil, fiber.NewError(fiber.StatusConflict, "email already exists")
+	_, err = s.repo.FindByEmail(ctx, req.Email)
+	if err == nil {
+		return nil, domain.ErrUserEmailAlreadyExists
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
 	hashedPassword, err := s.bcrypt.Hash(req.Password)
@@ -146,39 +175,54 @@ func (s *authService) RegisterWithEmail(ctx context.Context, req *dto.RegisterWi
 		Password: hashedPassword,
 	}
 
-	err = s.repo.RegisterWithEmail(ctx, user)
-	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
-	}
+	// The account is only kept when the verification code could be sent
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		err := s.repo.RegisterWithEmail(ctx, user)
+		if err != nil {
+			// Registered concurrently since it was checked
+			if domain.IsRequestError(err) {
+				return err
+			}
+
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
 
-	token, err := s.jwt.Create(user.ID)
+		return s.sendVerification(ctx, user.ID, dto.NotificationChannelEmail, req.Email)
+	})
 	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return nil, err
 	}
 
 	res := &dto.RegisterWithEmailResponse{
 		Email: user.Email.String,
 		Phone: "",
-		Token: token,
 	}
 
 	return res, nil
 }
 
-// RegisterWithPhone is a method to register with phone
+// RegisterWithPhone is a method to register with phone. The user logs in once
+// the phone is verified, a session before that would bypass the verification.
 func (s *authService) RegisterWithPhone(ctx context.Context, req *dto.RegisterWithPhoneRequest) (*dto.RegisterWithPhoneResponse, error) {
 	valErr := s.validator.Validate(req)
 	if valErr != nil {
 		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
 	}
 
-	_, err := s.repo.FindByPhone(ctx, req.Phone)
+	// An unverified holder whose code ran out doesn't keep the phone from its
+	// actual owner
+	err := s.otpService.ReleaseUnverified(ctx, entity.OneTimeCodeVerifyPhone, req.Phone)
 	if err != nil {
-		if !errors.Is(err, sql.ErrNoRows) {
-			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
-		}
+		return nil, err
+	}
 
-		return nil, fiber.NewError(fiber.StatusConflict, "phone already exists")
+	_, err = s.repo.FindByPhone(ctx, req.Phone)
+	if err == nil {
+		return nil, domain.ErrUserPhoneAlreadyExists
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
 	hashedPassword, err := s.bcrypt.Hash(req.Password)
@@ -191,20 +235,27 @@ func (s *authService) RegisterWithPhone(ctx context.Context, req *dto.RegisterWi
 		Password: hashedPassword,
 	}
 
-	err = s.repo.RegisterWithPhone(ctx, user)
-	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
-	}
+	// The account is only kept when the verification code could be sent
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		err := s.repo.RegisterWithPhone(ctx, user)
+		if err != nil {
+			// Registered concurrently since it was checked
+			if domain.IsRequestError(err) {
+				return err
+			}
 
-	token, err := s.jwt.Create(user.ID)
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return s.sendVerification(ctx, user.ID, dto.NotificationChannelPhone, req.Phone)
+	})
 	if err != nil {
-		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		return nil, err
 	}
 
 	res := &dto.RegisterWithPhoneResponse{
 		Email: "",
 		Phone: user.Phone.String,
-		Token: token,
 	}
 
 	return res, nil
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+// fakeUnitOfWork runs fn right away and counts how its transactions ended
+type fakeUnitOfWork struct {
+	committed  int
+	rolledBack int
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		u.rolledBack++
+		return err
+	}
+
+	u.committed++
+	return nil
+}
+
+type fakeAuthRepository struct {
+	users  map[int]*entity.User
+	nextID int
+}
+
+func newFakeAuthRepository(users ...*entity.User) *fakeAuthRepository {
+	r := &fakeAuthRepository{users: map[int]*entity.User{}, nextID: 1}
+	for _, user := range users {
+		r.users[user.ID] = user
+		r.nextID = max(r.nextID, user.ID+1)
+	}
+
+	return r
+}
+
+func (r *fakeAuthRepository) find(match func(user *entity.User) bool) (*entity.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			copied := *user
+			return &copied, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeAuthRepository) FindByEmail(_ context.Context, email string) (*entity.User, error) {
+	return r.find(func(user *entity.User) bool { return user.Email.Valid && user.Email.String == email })
+}
+
+func (r *fakeAuthRepository) FindByPhone(_ context.Context, phone string) (*entity.User, error) {
+	return r.find(func(user *entity.User) bool { return user.Phone.Valid && user.Phone.String == phone })
+}
+
+func (r *fakeAuthRepository) register(user *entity.User) error {
+	user.ID = r.nextID
+	r.nextID++
+
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeAuthRepository) RegisterWithEmail(_ context.Context, user *entity.User) error {
+	return r.register(user)
+}
+
+func (r *fakeAuthRepository) RegisterWithPhone(_ context.Context, user *entity.User) error {
+	return r.register(user)
+}
+
+func (r *fakeAuthRepository) UpdatePassword(_ context.Context, userID int, password string) error {
+	r.users[userID].Password = password
+	return nil
+}
+
+func (r *fakeAuthRepository) MarkEmailVerified(_ context.Context, userID int, email string) error {
+	if user := r.users[userID]; user.Email.String == email {
+		user.EmailVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	return nil
+}
+
+func (r *fakeAuthRepository) MarkPhoneVerified(_ context.Context, userID int, phone string) error {
+	if user := r.users[userID]; user.Phone.String == phone {
+		user.PhoneVerifiedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	return nil
+}
+
+type fakeSessionRepository struct {
+	sessions      map[string]*entity.Session
+	refreshTokens map[int]*entity.RefreshToken
+	nextTokenID   int
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{
+		sessions:      map[string]*entity.Session{},
+		refreshTokens: map[int]*entity.RefreshToken{},
+		nextTokenID:   1,
+	}
+}
+
+func (r *fakeSessionRepository) Create(_ context.Context, session *entity.Session) error {
+	copied := *session
+	r.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *fakeSessionRepository) FindByID(_ context.Context, id string) (*entity.Session, error) {
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	copied := *session
+	return &copied, nil
+}
+
+func (r *fakeSessionRepository) FindActiveByUserID(_ context.Context, userID int) ([]entity.Session, error) {
+	var sessions []entity.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && !session.RevokedAt.Valid {
+			sessions = append(sessions, *session)
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *fakeSessionRepository) IsActive(_ context.Context, id string) (bool, error) {
+	session, ok := r.sessions[id]
+	return ok && !session.RevokedAt.Valid, nil
+}
+
+func (r *fakeSessionRepository) Touch(_ context.Context, id string, ipAddress string) error {
+	r.sessions[id].IPAddress = ipAddress
+	return nil
+}
+
+func (r *fakeSessionRepository) Revoke(_ context.Context, id string, reason string) error {
+	session := r.sessions[id]
+	session.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	session.RevokedReason = reason
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeByUserID(ctx context.Context, userID int, keepID string, reason string) error {
+	for id, session := range r.sessions {
+		if session.UserID == userID && id != keepID && !session.RevokedAt.Valid {
+			r.Revoke(ctx, id, reason)
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeSessionRepository) CreateRefreshToken(_ context.Context, token *entity.RefreshToken, ttl time.Duration) error {
+	token.ID = r.nextTokenID
+	token.ExpiresAt = time.Now().Add(ttl)
+	r.nextTokenID++
+
+	copied := *token
+	r.refreshTokens[token.ID] = &copied
+	return nil
+}
+
+func (r *fakeSessionRepository) LockRefreshToken(_ context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	for _, token := range r.refreshTokens {
+		if token.TokenHash == tokenHash && token.ExpiresAt.After(time.Now()) {
+			copied := *token
+			return &copied, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeSessionRepository) MarkRefreshTokenUsed(_ context.Context, id int) error {
+	r.refreshTokens[id].UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+// fakeOneTimeCodeService records the codes it was asked to send. Contacts
+// listed in expired are released like ones whose first code has expired.
+type fakeOneTimeCodeService struct {
+	repo    *fakeAuthRepository
+	expired map[string]bool
+	issued  []string
+	err     error
+}
+
+func (s *fakeOneTimeCodeService) Issue(_ context.Context, userID int, purpose string, _ string, target string) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.issued = append(s.issued, fmt.Sprintf("%d %s %s", userID, purpose, target))
+	return nil
+}
+
+func (s *fakeOneTimeCodeService) Verify(context.Context, int, string, string, string) error {
+	return nil
+}
+
+func (s *fakeOneTimeCodeService) ReleaseUnverified(_ context.Context, purpose string, target string) error {
+	if !s.expired[target] {
+		return nil
+	}
+
+	for _, user := range s.repo.users {
+		if purpose == entity.OneTimeCodeVerifyEmail && user.Email.String == target && !user.EmailVerifiedAt.Valid {
+			user.Email = sql.NullString{}
+		}
+
+		if purpose == entity.OneTimeCodeVerifyPhone && user.Phone.String == target && !user.PhoneVerifiedAt.Valid {
+			user.Phone = sql.NullString{}
+		}
+	}
+
+	return nil
+}
+
+type fakeLoginLimiter struct{}
+
+func (fakeLoginLimiter) Blocked(context.Context, string) (time.Duration, error) { return 0, nil }
+func (fakeLoginLimiter) Fail(context.Context, string) (time.Duration, error)    { return 0, nil }
+func (fakeLoginLimiter) Reset(context.Context, string) error                    { return nil }
+func (fakeLoginLimiter) Prune(context.Context) error                            { return nil }
+
+// fakeBcrypt skips the cost of real hashing
+type fakeBcrypt struct{}
+
+func (fakeBcrypt) Hash(plain string) (string, error)    { return "hashed:" + plain, nil }
+func (fakeBcrypt) Compare(password, hashed string) bool { return "hashed:"+password == hashed }
+
+type fakeJwt struct{}
+
+func (fakeJwt) Create(userID int, sessionID string) (string, error) {
+	return fmt.Sprintf("access:%d:%s", userID, sessionID), nil
+}
+
+func (fakeJwt) Decode(string, *jwt.Claims) error { return nil }
+func (fakeJwt) JWKS() jwt.JWKS                   { return jwt.JWKS{} }
+
+type authFixture struct {
+	service  *authService
+	repo     *fakeAuthRepository
+	sessions *fakeSessionRepository
+	otp      *fakeOneTimeCodeService
+	uow      *fakeUnitOfWork
+}
+
+func newAuthFixture(users ...*entity.User) *authFixture {
+	repo := newFakeAuthRepository(users...)
+	f := &authFixture{
+		repo:     repo,
+		sessions: newFakeSessionRepository(),
+		otp:      &fakeOneTimeCodeService{repo: repo, expired: map[string]bool{}},
+		uow:      &fakeUnitOfWork{},
+	}
+
+	f.service = NewAuthService(
+		f.repo,
+		f.sessions,
+		f.otp,
+		fakeLoginLimiter{},
+		fakeLoginLimiter{},
+		f.uow,
+		validator.Validator,
+		fakeBcrypt{},
+		fakeJwt{},
+		time.Hour,
+	).(*authService)
+
+	return f
+}
+
+func TestRegisterWithEmail(t *testing.T) {
+	verified := sql.NullTime{Time: time.Now(), Valid: true}
+
+	tests := []struct {
+		name       string
+		users      []*entity.User
+		expired    []string
+		otpErr     error
+		wantErr    error
+		wantIssued bool
+	}{
+		{
+			name:       "new email",
+			wantIssued: true,
+		},
+		{
+			name:    "email of a verified user",
+			users:   []*entity.User{{ID: 1, Email: sql.NullString{String: "buyer@example.com", Valid: true}, EmailVerifiedAt: verified}},
+			expired: []string{"buyer@example.com"},
+			wantErr: domain.ErrUserEmailAlreadyExists,
+		},
+		{
+			name:    "email of an unverified user whose code is still valid",
+			users:   []*entity.User{{ID: 1, Email: sql.NullString{String: "buyer@example.com", Valid: true}}},
+			wantErr: domain.ErrUserEmailAlreadyExists,
+		},
+		{
+			name:       "email of an unverified user whose code expired",
+			users:      []*entity.User{{ID: 1, Email: sql.NullString{String: "buyer@example.com", Valid: true}}},
+			expired:    []string{"buyer@example.com"},
+			wantIssued: true,
+		},
+		{
+			name:    "code can't be sent",
+			otpErr:  errors.New("notifier is down"),
+			wantErr: errors.New("notifier is down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthFixture(tt.users...)
+			f.otp.err = tt.otpErr
+			for _, target := range tt.expired {
+				f.otp.expired[target] = true
+			}
+
+			res, err := f.service.RegisterWithEmail(context.Background(), &dto.RegisterWithEmailRequest{
+				Email:    "buyer@example.com",
+				Password: "password123",
+			})
+			if (err == nil) != (tt.wantErr == nil) || (err != nil && err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("RegisterWithEmail() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if len(f.sessions.sessions) != 0 || len(f.sessions.refreshTokens) != 0 {
+				t.Errorf("RegisterWithEmail() started a session for an unverified email")
+			}
+
+			if (len(f.otp.issued) == 1) != tt.wantIssued {
+				t.Errorf("RegisterWithEmail() sent codes %v, want a code sent = %v", f.otp.issued, tt.wantIssued)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if res.Email != "buyer@example.com" {
+				t.Errorf("RegisterWithEmail() email = %q, want %q", res.Email, "buyer@example.com")
+			}
+
+			user, err := f.repo.FindByEmail(context.Background(), "buyer@example.com")
+			if err != nil || user.EmailVerifiedAt.Valid {
+				t.Errorf("registered user = %+v, %v, want an unverified user", user, err)
+			}
+		})
+	}
+}
+
+func TestLoginRequiresVerifiedContact(t *testing.T) {
+	f := newAuthFixture()
+	ctx := context.Background()
+	client := dto.ClientInfo{UserAgent: "test", IPAddress: "192.0.2.1"}
+
+	_, err := f.service.RegisterWithPhone(ctx, &dto.RegisterWithPhoneRequest{Phone: "+6281234567890", Password: "password123"})
+	if err != nil {
+		t.Fatalf("RegisterWithPhone() error = %v", err)
+	}
+
+	_, err = f.service.LoginWithPhone(ctx, &dto.LoginWithPhoneRequest{Phone: "+6281234567890", Password: "password123"}, client)
+	if !errors.Is(err, domain.ErrPhoneNotVerified) {
+		t.Fatalf("LoginWithPhone() before verifying error = %v, want %v", err, domain.ErrPhoneNotVerified)
+	}
+
+	err = f.service.VerifyContact(ctx, &dto.VerifyContactRequest{Phone: "+6281234567890", Code: "123456"})
+	if err != nil {
+		t.Fatalf("VerifyContact() error = %v", err)
+	}
+
+	res, err := f.service.LoginWithPhone(ctx, &dto.LoginWithPhoneRequest{Phone: "+6281234567890", Password: "password123"}, client)
+	if err != nil {
+		t.Fatalf("LoginWithPhone() after verifying error = %v", err)
+	}
+
+	if res.Token == "" || res.RefreshToken == "" || len(f.sessions.sessions) != 1 {
+		t.Errorf("LoginWithPhone() = %+v with %d sessions, want tokens of one session", res, len(f.sessions.sessions))
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// Refresh implements contracts.AuthService.
+func (s *authService) Refresh(ctx context.Context, req *dto.RefreshTokenRequest, client dto.ClientInfo) (*dto.RefreshTokenResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	var res *dto.RefreshTokenResponse
+	reused := false
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		refreshToken, err := s.sessionRepo.LockRefreshToken(ctx, hashToken(req.RefreshToken))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrInvalidRefreshToken
+			}
+
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		session, err := s.sessionRepo.FindByID(ctx, refreshToken.SessionID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if session.RevokedAt.Valid {
+			return domain.ErrInvalidRefreshToken
+		}
+
+		// The revocation has to be committed, so the error is only returned
+		// once the transaction is done
+		if refreshToken.UsedAt.Valid {
+			reused = true
+
+			err := s.sessionRepo.Revoke(ctx, session.ID, entity.SessionRevokedTokenReused)
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+
+			return nil
+		}
+
+		err = s.sessionRepo.MarkRefreshTokenUsed(ctx, refreshToken.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		err = s.sessionRepo.Touch(ctx, session.ID, client.IPAddress)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		newRefreshToken, err := s.createRefreshToken(ctx, session.ID)
+		if err != nil {
+			return err
+		}
+
+		token, err := s.jwt.Create(session.UserID, session.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		res = &dto.RefreshTokenResponse{
+			Token:        token,
+			RefreshToken: newRefreshToken,
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused {
+		return nil, domain.ErrRefreshTokenReused
+	}
+
+	return res, nil
+}
+
+// Logout implements contracts.AuthService.
+func (s *authService) Logout(ctx context.Context, sessionID string) error {
+	err := s.sessionRepo.Revoke(ctx, sessionID, entity.SessionRevokedLogout)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// issueTokens starts a session for the user on the client and returns its
+// access token and first refresh token
+func (s *authService) issueTokens(ctx context.Context, userID int, client dto.ClientInfo) (string, string, error) {
+	sessionID, err := randomBytes(16)
+	if err != nil {
+		return "", "", fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	session := &entity.Session{
+		ID:        hex.EncodeToString(sessionID),
+		UserID:    userID,
+		UserAgent: client.UserAgent,
+		IPAddress: client.IPAddress,
+	}
+
+	var refreshToken string
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		err := s.sessionRepo.Create(ctx, session)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		refreshToken, err = s.createRefreshToken(ctx, session.ID)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	token, err := s.jwt.Create(userID, session.ID)
+	if err != nil {
+		return "", "", fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return token, refreshToken, nil
+}
+
+// createRefreshToken adds a refresh token to the session. Only its hash is
+// stored, the token itself is handed to the client once.
+func (s *authService) createRefreshToken(ctx context.Context, sessionID string) (string, error) {
+	raw, err := randomBytes(32)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	err = s.sessionRepo.CreateRefreshToken(ctx, &entity.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: hashToken(token),
+	}, s.refreshTokenTTL)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return token, nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	return b, err
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
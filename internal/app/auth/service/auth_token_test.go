@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// loggedIn logs a verified user in and returns the fixture along with the
+// first refresh token of the session
+func loggedIn(t *testing.T) (*authFixture, string) {
+	t.Helper()
+
+	f := newAuthFixture(&entity.User{
+		ID:              1,
+		Email:           sql.NullString{String: "buyer@example.com", Valid: true},
+		EmailVerifiedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		Password:        "hashed:password123",
+	})
+
+	res, err := f.service.LoginWithEmail(context.Background(),
+		&dto.LoginWithEmailRequest{Email: "buyer@example.com", Password: "password123"},
+		dto.ClientInfo{UserAgent: "test", IPAddress: "192.0.2.1"},
+	)
+	if err != nil {
+		t.Fatalf("LoginWithEmail() error = %v", err)
+	}
+
+	return f, res.RefreshToken
+}
+
+func (f *authFixture) refresh(token string) (*dto.RefreshTokenResponse, error) {
+	return f.service.Refresh(context.Background(), &dto.RefreshTokenRequest{RefreshToken: token}, dto.ClientInfo{UserAgent: "test", IPAddress: "192.0.2.2"})
+}
+
+func (f *authFixture) onlySession(t *testing.T) *entity.Session {
+	t.Helper()
+
+	if len(f.sessions.sessions) != 1 {
+		t.Fatalf("%d sessions, want 1", len(f.sessions.sessions))
+	}
+
+	for _, session := range f.sessions.sessions {
+		return session
+	}
+
+	return nil
+}
+
+func TestRefreshRotatesTheToken(t *testing.T) {
+	f, first := loggedIn(t)
+
+	res, err := f.refresh(first)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	if res.Token == "" || res.RefreshToken == "" || res.RefreshToken == first {
+		t.Fatalf("Refresh() = %+v, want a new token pair", res)
+	}
+
+	session := f.onlySession(t)
+	if session.RevokedAt.Valid {
+		t.Errorf("session revoked by a regular refresh")
+	}
+
+	if session.IPAddress != "192.0.2.2" {
+		t.Errorf("session IP = %s, want the IP of the refresh", session.IPAddress)
+	}
+
+	// The rotated token keeps the session going
+	if _, err := f.refresh(res.RefreshToken); err != nil {
+		t.Errorf("Refresh() with the rotated token error = %v", err)
+	}
+}
+
+func TestRefreshWithReusedTokenRevokesTheSession(t *testing.T) {
+	f, first := loggedIn(t)
+
+	res, err := f.refresh(first)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	_, err = f.refresh(first)
+	if !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("Refresh() with a used token error = %v, want %v", err, domain.ErrRefreshTokenReused)
+	}
+
+	session := f.onlySession(t)
+	if !session.RevokedAt.Valid || session.RevokedReason != entity.SessionRevokedTokenReused {
+		t.Errorf("session revoked = %v (%q), want it revoked for reuse", session.RevokedAt.Valid, session.RevokedReason)
+	}
+
+	if f.uow.rolledBack != 0 {
+		t.Errorf("revocation rolled back, it has to be committed")
+	}
+
+	// Whoever holds the latest token is signed out as well
+	_, err = f.refresh(res.RefreshToken)
+	if !errors.Is(err, domain.ErrInvalidRefreshToken) {
+		t.Errorf("Refresh() with the token of a revoked session error = %v, want %v", err, domain.ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshRejectsInvalidTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(f *authFixture, token string) string
+	}{
+		{
+			name:  "unknown token",
+			setup: func(*authFixture, string) string { return "unknown" },
+		},
+		{
+			name: "expired token",
+			setup: func(f *authFixture, token string) string {
+				for _, refreshToken := range f.sessions.refreshTokens {
+					refreshToken.ExpiresAt = time.Now().Add(-time.Second)
+				}
+				return token
+			},
+		},
+		{
+			name: "logged out session",
+			setup: func(f *authFixture, token string) string {
+				if err := f.service.Logout(context.Background(), f.onlySession(t).ID); err != nil {
+					t.Fatalf("Logout() error = %v", err)
+				}
+				return token
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, token := loggedIn(t)
+
+			_, err := f.refresh(tt.setup(f, token))
+			if !errors.Is(err, domain.ErrInvalidRefreshToken) {
+				t.Fatalf("Refresh() error = %v, want %v", err, domain.ErrInvalidRefreshToken)
+			}
+
+			if len(f.sessions.refreshTokens) != 1 {
+				t.Errorf("%d refresh tokens, want no new one", len(f.sessions.refreshTokens))
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// SendVerification implements contracts.AuthService.
+func (s *authService) SendVerification(ctx context.Context, req *dto.SendVerificationRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	user, channel, target, err := s.findByContact(ctx, req.Email, req.Phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if contactVerified(user, channel) {
+		return nil
+	}
+
+	err = s.otpService.Issue(ctx, user.ID, verificationPurpose(channel), channel, target)
+	if err != nil {
+		if errors.Is(err, domain.ErrOneTimeCodeTooSoon) {
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// VerifyContact implements contracts.AuthService.
+func (s *authService) VerifyContact(ctx context.Context, req *dto.VerifyContactRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	user, channel, target, err := s.findByContact(ctx, req.Email, req.Phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrInvalidOneTimeCode
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	err = s.otpService.Verify(ctx, user.ID, verificationPurpose(channel), target, req.Code)
+	if err != nil {
+		return err
+	}
+
+	if channel == dto.NotificationChannelEmail {
+		err = s.repo.MarkEmailVerified(ctx, user.ID, target)
+	} else {
+		err = s.repo.MarkPhoneVerified(ctx, user.ID, target)
+	}
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// sendVerification sends the code for a contact the user just registered
+func (s *authService) sendVerification(ctx context.Context, userID int, channel string, target string) error {
+	return s.otpService.Issue(ctx, userID, verificationPurpose(channel), channel, target)
+}
+
+func verificationPurpose(channel string) string {
+	if channel == dto.NotificationChannelEmail {
+		return entity.OneTimeCodeVerifyEmail
+	}
+
+	return entity.OneTimeCodeVerifyPhone
+}
+
+func contactVerified(user *entity.User, channel string) bool {
+	if channel == dto.NotificationChannelEmail {
+		return user.EmailVerifiedAt.Valid
+	}
+
+	return user.PhoneVerifiedAt.Valid
+}
@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+)
+
+// cartTokenHeader carries the cart token of guests
+const cartTokenHeader = "X-Cart-Token"
+
+type cartController struct {
+	service contracts.CartService
+}
+
+func InitCartController(router fiber.Router, service contracts.CartService, middleware *middlewares.Middleware) {
+	controller := &cartController{
+		service,
+	}
+
+	cartRouter := router.Group("/cart", middleware.OptionalAuth())
+
+	cartRouter.Get("/", controller.getCart)
+	cartRouter.Post("/items", controller.addItem)
+	cartRouter.Put("/items/:productId", controller.updateItem)
+	cartRouter.Delete("/items/:productId", controller.removeItem)
+	cartRouter.Post("/checkout", middleware.Idempotency(), controller.checkout)
+}
+
+func (c *cartController) getCart(ctx *fiber.Ctx) error {
+	res, err := c.service.GetCart(ctx.Context(), optionalUserID(ctx), ctx.Get(cartTokenHeader))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *cartController) addItem(ctx *fiber.Ctx) error {
+	var req dto.AddCartItemRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.AddItem(ctx.Context(), optionalUserID(ctx), ctx.Get(cartTokenHeader), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *cartController) updateItem(ctx *fiber.Ctx) error {
+	var req dto.UpdateCartItemRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.UpdateItem(ctx.Context(), optionalUserID(ctx), ctx.Get(cartTokenHeader), ctx.Params("productId"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *cartController) removeItem(ctx *fiber.Ctx) error {
+	res, err := c.service.RemoveItem(ctx.Context(), optionalUserID(ctx), ctx.Get(cartTokenHeader), ctx.Params("productId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *cartController) checkout(ctx *fiber.Ctx) error {
+	var req dto.CheckoutCartRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.Checkout(ctx.Context(), optionalUserID(ctx), ctx.Get(cartTokenHeader), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(res)
+}
+
+func optionalUserID(ctx *fiber.Ctx) int {
+	claims, ok := ctx.Locals("claims").(jwt.Claims)
+	if !ok {
+		return 0
+	}
+
+	return claims.UserID
+}
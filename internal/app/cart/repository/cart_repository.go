@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+)
+
+type cartRepository struct {
+	db *sqlx.DB
+}
+
+func NewCartRepository(db *sqlx.DB) contracts.CartRepository {
+	return &cartRepository{db}
+}
+
+// FindByID implements contracts.CartRepository.
+func (r *cartRepository) FindByID(ctx context.Context, id int) (*entity.Cart, error) {
+	var cart entity.Cart
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &cart, "SELECT * FROM carts WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cart, nil
+}
+
+// FindByUserID implements contracts.CartRepository.
+func (r *cartRepository) FindByUserID(ctx context.Context, userID int) (*entity.Cart, error) {
+	var cart entity.Cart
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &cart, "SELECT * FROM carts WHERE user_id = $1", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cart, nil
+}
+
+// Create implements contracts.CartRepository. A user keeps one cart, creating
+// it again returns the existing one.
+func (r *cartRepository) Create(ctx context.Context, cart *entity.Cart) error {
+	return database.Conn(ctx, r.db).QueryRowxContext(ctx, `
+		INSERT INTO carts (user_id) VALUES ($1)
+		ON CONFLICT (user_id) DO UPDATE SET updated_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at
+	`,
+		cart.UserID,
+	).Scan(&cart.ID, &cart.CreatedAt, &cart.UpdatedAt)
+}
+
+// AssignUser implements contracts.CartRepository.
+func (r *cartRepository) AssignUser(ctx context.Context, cartID int, userID int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE carts SET user_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", userID, cartID)
+	return err
+}
+
+// Delete implements contracts.CartRepository.
+func (r *cartRepository) Delete(ctx context.Context, id int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM carts WHERE id = $1", id)
+	return err
+}
+
+// GetItems implements contracts.CartRepository.
+func (r *cartRepository) GetItems(ctx context.Context, cartID int) ([]entity.CartItem, error) {
+	var items []entity.CartItem
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &items, "SELECT * FROM cart_items WHERE cart_id = $1 ORDER BY created_at, id", cartID)
+	return items, err
+}
+
+// AddItem implements contracts.CartRepository. Adding a product that is
+// already in the cart adds to its quantity.
+func (r *cartRepository) AddItem(ctx context.Context, item *entity.CartItem) error {
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), `
+		INSERT INTO cart_items (cart_id, product_id, qty, price)
+		VALUES (:cart_id, :product_id, :qty, :price)
+		ON CONFLICT (cart_id, product_id) DO UPDATE
+		SET qty = cart_items.qty + EXCLUDED.qty, price = EXCLUDED.price, updated_at = CURRENT_TIMESTAMP
+		RETURNING id, qty, created_at, updated_at
+	`, item)
+	if err != nil {
+		return database.MapError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return database.MapError(err)
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&item.ID, &item.Quantity, &item.CreatedAt, &item.UpdatedAt)
+}
+
+// UpdateItem implements contracts.CartRepository.
+func (r *cartRepository) UpdateItem(ctx context.Context, item *entity.CartItem) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE cart_items SET qty = $1, price = $2, updated_at = CURRENT_TIMESTAMP WHERE cart_id = $3 AND product_id = $4",
+		item.Quantity, item.Price, item.CartID, item.ProductID,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// DeleteItem implements contracts.CartRepository.
+func (r *cartRepository) DeleteItem(ctx context.Context, cartID int, productID int) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = $1 AND product_id = $2", cartID, productID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// ClearItems implements contracts.CartRepository.
+func (r *cartRepository) ClearItems(ctx context.Context, cartID int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM cart_items WHERE cart_id = $1", cartID)
+	return err
+}
+
+// MergeItems implements contracts.CartRepository. Products in both carts end
+// up with the quantities added together.
+func (r *cartRepository) MergeItems(ctx context.Context, fromCartID int, toCartID int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO cart_items (cart_id, product_id, qty, price)
+		SELECT $2, product_id, qty, price FROM cart_items WHERE cart_id = $1
+		ON CONFLICT (cart_id, product_id) DO UPDATE
+		SET qty = cart_items.qty + EXCLUDED.qty, updated_at = CURRENT_TIMESTAMP
+	`, fromCartID, toCartID)
+	return err
+}
+
+// GetProductsByIDs implements contracts.CartRepository.
+func (r *cartRepository) GetProductsByIDs(ctx context.Context, ids []int) ([]entity.Product, error) {
+	var products []entity.Product
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &products, "SELECT * FROM products WHERE id = ANY($1)", ids)
+	return products, err
+}
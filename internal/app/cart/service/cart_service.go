@@ -0,0 +1,478 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/signature"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+type cartService struct {
+	repo            contracts.CartRepository
+	uow             contracts.UnitOfWork
+	purchaseService contracts.PurchaseService
+	validator       validator.ValidatorInterface
+	signature       signature.SignatureInterface
+}
+
+func NewCartService(
+	repo contracts.CartRepository,
+	uow contracts.UnitOfWork,
+	purchaseService contracts.PurchaseService,
+	validator validator.ValidatorInterface,
+	signature signature.SignatureInterface,
+) contracts.CartService {
+	return &cartService{
+		repo,
+		uow,
+		purchaseService,
+		validator,
+		signature,
+	}
+}
+
+// GetCart implements contracts.CartService.
+func (s *cartService) GetCart(ctx context.Context, userID int, cartToken string) (*dto.CartResponse, error) {
+	var res *dto.CartResponse
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		cart, err := s.resolveCart(ctx, userID, cartToken, false)
+		if err != nil {
+			return err
+		}
+
+		res, err = s.toCartResponse(ctx, cart)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// AddItem implements contracts.CartService.
+func (s *cartService) AddItem(ctx context.Context, userID int, cartToken string, req *dto.AddCartItemRequest) (*dto.CartResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	var res *dto.CartResponse
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		product, err := s.findProduct(ctx, req.ProductID)
+		if err != nil {
+			return err
+		}
+
+		cart, err := s.resolveCart(ctx, userID, cartToken, true)
+		if err != nil {
+			return err
+		}
+
+		err = s.repo.AddItem(ctx, &entity.CartItem{
+			CartID:    cart.ID,
+			ProductID: product.ID,
+			Quantity:  req.Qty,
+			Price:     product.Price,
+		})
+		if err != nil {
+			if domain.IsRequestError(err) {
+				return err
+			}
+
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		res, err = s.toCartResponse(ctx, cart)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// UpdateItem implements contracts.CartService. The item takes the current
+// price of the product, the buyer has seen it when changing the quantity.
+func (s *cartService) UpdateItem(ctx context.Context, userID int, cartToken string, productID string, req *dto.UpdateCartItemRequest) (*dto.CartResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	var res *dto.CartResponse
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		cart, err := s.resolveCart(ctx, userID, cartToken, false)
+		if err != nil {
+			return err
+		}
+
+		if cart == nil {
+			return domain.ErrCartItemNotFound
+		}
+
+		product, err := s.findProduct(ctx, productID)
+		if err != nil {
+			return err
+		}
+
+		err = s.repo.UpdateItem(ctx, &entity.CartItem{
+			CartID:    cart.ID,
+			ProductID: product.ID,
+			Quantity:  req.Qty,
+			Price:     product.Price,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrCartItemNotFound
+			}
+
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		res, err = s.toCartResponse(ctx, cart)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RemoveItem implements contracts.CartService.
+func (s *cartService) RemoveItem(ctx context.Context, userID int, cartToken string, productID string) (*dto.CartResponse, error) {
+	id, err := strconv.Atoi(productID)
+	if err != nil {
+		return nil, domain.ErrCartItemNotFound
+	}
+
+	var res *dto.CartResponse
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		cart, err := s.resolveCart(ctx, userID, cartToken, false)
+		if err != nil {
+			return err
+		}
+
+		if cart == nil {
+			return domain.ErrCartItemNotFound
+		}
+
+		err = s.repo.DeleteItem(ctx, cart.ID, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrCartItemNotFound
+			}
+
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		res, err = s.toCartResponse(ctx, cart)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Checkout implements contracts.CartService. The cart is bought through the
+// purchase service and emptied in the same transaction. When prices changed
+// since the items were added, the cart takes the new prices and the checkout
+// is refused, so the buyer never pays a price they haven't seen.
+func (s *cartService) Checkout(ctx context.Context, userID int, cartToken string, req *dto.CheckoutCartRequest) (dto.PurchaseResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return dto.PurchaseResponse{}, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	var res dto.PurchaseResponse
+	priceChanged := false
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		cart, err := s.resolveCart(ctx, userID, cartToken, false)
+		if err != nil {
+			return err
+		}
+
+		if cart == nil {
+			return domain.ErrCartEmpty
+		}
+
+		items, products, err := s.getItems(ctx, cart.ID)
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return domain.ErrCartEmpty
+		}
+
+		purchasedItems := make([]dto.PurchaseItemRequest, 0, len(items))
+		for _, item := range items {
+			product := products[item.ProductID]
+			if item.Quantity > product.Quantity {
+				return domain.ErrCartOutOfStock
+			}
+
+			if item.Price.Cmp(product.Price) != 0 {
+				priceChanged = true
+
+				item.Price = product.Price
+				err := s.repo.UpdateItem(ctx, &item)
+				if err != nil {
+					return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+				}
+			}
+
+			purchasedItems = append(purchasedItems, dto.PurchaseItemRequest{
+				ProductID: strconv.Itoa(item.ProductID),
+				Qty:       item.Quantity,
+			})
+		}
+
+		// Keep the new prices, the buyer gets to review them first
+		if priceChanged {
+			return nil
+		}
+
+		res, err = s.purchaseService.Purchase(ctx, userID, dto.PurchaseRequest{
+			PurchasedItems:      purchasedItems,
+			SenderName:          req.SenderName,
+			SenderContactType:   req.SenderContactType,
+			SenderContactDetail: req.SenderContactDetail,
+			VoucherCode:         req.VoucherCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = s.repo.ClearItems(ctx, cart.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.PurchaseResponse{}, err
+	}
+
+	if priceChanged {
+		return dto.PurchaseResponse{}, domain.ErrCartPriceChanged
+	}
+
+	return res, nil
+}
+
+// resolveCart finds the cart of the user, or of the guest holding cartToken.
+// A guest cart sent by a logged in user becomes theirs, or is merged into the
+// cart they already have. Without create a missing cart is nil.
+func (s *cartService) resolveCart(ctx context.Context, userID int, cartToken string, create bool) (*entity.Cart, error) {
+	guestCart, err := s.findGuestCart(ctx, cartToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == 0 {
+		if guestCart != nil || !create {
+			return guestCart, nil
+		}
+
+		cart := &entity.Cart{}
+		err := s.repo.Create(ctx, cart)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return cart, nil
+	}
+
+	cart, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		cart = nil
+	}
+
+	if guestCart != nil {
+		if cart == nil {
+			err := s.repo.AssignUser(ctx, guestCart.ID, userID)
+			if err != nil {
+				return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+
+			guestCart.UserID = sql.NullInt32{Int32: int32(userID), Valid: true}
+			return guestCart, nil
+		}
+
+		err := s.repo.MergeItems(ctx, guestCart.ID, cart.ID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		err = s.repo.Delete(ctx, guestCart.ID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	if cart == nil && create {
+		cart = &entity.Cart{UserID: sql.NullInt32{Int32: int32(userID), Valid: true}}
+		err := s.repo.Create(ctx, cart)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
+
+	return cart, nil
+}
+
+// findGuestCart returns the guest cart of cartToken. Carts that were deleted or
+// taken over by a user since the token was issued are nil.
+func (s *cartService) findGuestCart(ctx context.Context, cartToken string) (*entity.Cart, error) {
+	if cartToken == "" {
+		return nil, nil
+	}
+
+	cartID, sig, ok := strings.Cut(cartToken, ".")
+	if !ok || !s.signature.Verify(cartTokenValue(cartID), sig) {
+		return nil, domain.ErrInvalidCartToken
+	}
+
+	id, err := strconv.Atoi(cartID)
+	if err != nil {
+		return nil, domain.ErrInvalidCartToken
+	}
+
+	cart, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if cart.UserID.Valid {
+		return nil, nil
+	}
+
+	return cart, nil
+}
+
+func (s *cartService) findProduct(ctx context.Context, productID string) (*entity.Product, error) {
+	id, err := strconv.Atoi(productID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "product not found")
+	}
+
+	products, err := s.repo.GetProductsByIDs(ctx, []int{id})
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if len(products) == 0 {
+		return nil, fiber.NewError(fiber.StatusNotFound, "product not found")
+	}
+
+	return &products[0], nil
+}
+
+// getItems returns the items of the cart with their products as they are now
+func (s *cartService) getItems(ctx context.Context, cartID int) ([]entity.CartItem, map[int]entity.Product, error) {
+	items, err := s.repo.GetItems(ctx, cartID)
+	if err != nil {
+		return nil, nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	productIDs := make([]int, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	products := make(map[int]entity.Product, len(items))
+	if len(productIDs) > 0 {
+		found, err := s.repo.GetProductsByIDs(ctx, productIDs)
+		if err != nil {
+			return nil, nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		for _, product := range found {
+			products[product.ID] = product
+		}
+	}
+
+	return items, products, nil
+}
+
+// toCartResponse checks every item against the current price and stock of
+// its product
+func (s *cartService) toCartResponse(ctx context.Context, cart *entity.Cart) (*dto.CartResponse, error) {
+	res := &dto.CartResponse{
+		Items:      []dto.CartItemResponse{},
+		TotalPrice: money.New(0, money.DefaultCurrency),
+		Currency:   money.DefaultCurrency,
+	}
+
+	if cart == nil {
+		return res, nil
+	}
+
+	if !cart.UserID.Valid {
+		cartID := strconv.Itoa(cart.ID)
+		res.CartToken = cartID + "." + s.signature.Sign(cartTokenValue(cartID))
+	}
+
+	items, products, err := s.getItems(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res.CanCheckout = len(items) > 0
+	for _, item := range items {
+		product := products[item.ProductID]
+		subtotal := product.Price.Mul(int64(item.Quantity))
+		inStock := item.Quantity <= product.Quantity
+
+		res.Items = append(res.Items, dto.CartItemResponse{
+			ProductID:        strconv.Itoa(product.ID),
+			SellerID:         strconv.Itoa(product.UserID),
+			Name:             product.Name,
+			Category:         product.Category,
+			SKU:              product.SKU,
+			FileThumbnailURI: product.FileThumbnailURL,
+			Qty:              item.Quantity,
+			AvailableQty:     product.Quantity,
+			InStock:          inStock,
+			Price:            product.Price,
+			AddedPrice:       item.Price,
+			PriceChanged:     item.Price.Cmp(product.Price) != 0,
+			Subtotal:         subtotal,
+		})
+
+		res.TotalPrice = res.TotalPrice.Add(subtotal)
+		res.CanCheckout = res.CanCheckout && inStock
+	}
+
+	return res, nil
+}
+
+// cartTokenValue is what a cart token signs, it differs from purchase lookup
+// tokens so one can't be used as the other
+func cartTokenValue(cartID string) string {
+	return "cart:" + cartID
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+)
+
+type fileController struct {
+	service contracts.FileService
+}
+
+func InitFileController(router fiber.Router, service contracts.FileService, middleware *middlewares.Middleware) {
+	controller := &fileController{
+		service,
+	}
+
+	fileRouter := router.Group("/file")
+
+	fileRouter.Post("/", middleware.OptionalAuth(), controller.uploadFile)
+}
+
+// uploadFile stores a file owned by the user. Guests have to name the purchase
+// they pay for and send its lookup token instead.
+func (c *fileController) uploadFile(ctx *fiber.Ctx) error {
+	claims, isUser := ctx.Locals("claims").(jwt.Claims)
+	if !isUser && ctx.Query("purchaseId") == "" {
+		return domain.ErrNoBearerToken
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+
+	var res *dto.UploadFileResponse
+	if isUser {
+		res, err = c.service.Upload(ctx.Context(), claims.UserID, fileHeader)
+	} else {
+		res, err = c.service.UploadForPurchase(ctx.Context(), ctx.Query("purchaseId"), ctx.Get(middlewares.LookupTokenHeader), fileHeader)
+	}
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
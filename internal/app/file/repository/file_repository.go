@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+)
+
+type fileRepository struct {
+	db *sqlx.DB
+}
+
+func NewFileRepository(db *sqlx.DB) contracts.FileRepository {
+	return &fileRepository{db}
+}
+
+// FindByID implements contracts.FileRepository.
+func (r *fileRepository) FindByID(ctx context.Context, id int) (*entity.File, error) {
+	var file entity.File
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &file, "SELECT id, user_id, purchase_id, file_uri, file_thumbnail_uri, created_at FROM files WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &file, nil
+}
+
+// Create implements contracts.FileRepository.
+func (r *fileRepository) Create(ctx context.Context, file *entity.File) error {
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), `
+		INSERT INTO files (user_id, purchase_id, file_uri, file_thumbnail_uri)
+		VALUES (:user_id, :purchase_id, :file_uri, :file_thumbnail_uri)
+		RETURNING id, created_at
+	`, file)
+	if err != nil {
+		return database.MapError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return database.MapError(err)
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&file.ID, &file.CreatedAt)
+}
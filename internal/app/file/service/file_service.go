@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/signature"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/thumbnail"
+)
+
+// allowedMimeTypes maps the accepted file extensions to their mime type
+var allowedMimeTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+}
+
+type fileService struct {
+	repo        contracts.FileRepository
+	storage     contracts.FileStorage
+	thumbnail   thumbnail.ThumbnailInterface
+	signature   signature.SignatureInterface
+	maxFileSize int64
+}
+
+func NewFileService(repo contracts.FileRepository, storage contracts.FileStorage, thumbnail thumbnail.ThumbnailInterface, signature signature.SignatureInterface, maxFileSize int64) contracts.FileService {
+	return &fileService{
+		repo,
+		storage,
+		thumbnail,
+		signature,
+		maxFileSize,
+	}
+}
+
+// Upload implements contracts.FileService.
+func (s *fileService) Upload(ctx context.Context, userID int, fileHeader *multipart.FileHeader) (*dto.UploadFileResponse, error) {
+	return s.upload(ctx, &entity.File{
+		UserID: sql.NullInt32{Int32: int32(userID), Valid: true},
+	}, fileHeader)
+}
+
+// UploadForPurchase stores a file for a guest, who has no user to own it. The
+// file is bound to the purchase whose lookup token was given instead, so it
+// can only be used as a payment proof of that purchase.
+func (s *fileService) UploadForPurchase(ctx context.Context, purchaseID string, lookupToken string, fileHeader *multipart.FileHeader) (*dto.UploadFileResponse, error) {
+	id, err := strconv.Atoi(purchaseID)
+	if err != nil || lookupToken == "" || !s.signature.VerifyWithExpiry(purchaseID, lookupToken) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "purchase not found")
+	}
+
+	return s.upload(ctx, &entity.File{
+		PurchaseID: sql.NullInt32{Int32: int32(id), Valid: true},
+	}, fileHeader)
+}
+
+// upload validates the content, stores it along with its thumbnail and
+// records the file with the given owner
+func (s *fileService) upload(ctx context.Context, file *entity.File, fileHeader *multipart.FileHeader) (*dto.UploadFileResponse, error) {
+	if fileHeader.Size > s.maxFileSize {
+		return nil, domain.ErrFileSizeLimitExceeded
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	mimeType, ok := allowedMimeTypes[ext]
+	if !ok {
+		return nil, domain.ErrInvalidFileExtension
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	defer src.Close()
+
+	content, err := io.ReadAll(io.LimitReader(src, s.maxFileSize+1))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	if int64(len(content)) > s.maxFileSize {
+		return nil, domain.ErrFileSizeLimitExceeded
+	}
+
+	if http.DetectContentType(content) != mimeType {
+		return nil, domain.ErrInvalidMimeType
+	}
+
+	thumbnailContent, err := s.thumbnail.Generate(ctx, content)
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, fiber.NewError(fiber.StatusServiceUnavailable, err.Error())
+		}
+
+		return nil, fiber.NewError(fiber.StatusBadRequest, "failed to process image: "+err.Error())
+	}
+
+	name := uuid.NewString()
+	key := name + ext
+	thumbnailKey := name + "_thumbnail" + ext
+
+	fileURI, err := s.storage.Put(ctx, key, mimeType, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	fileThumbnailURI, err := s.storage.Put(ctx, thumbnailKey, mimeType, bytes.NewReader(thumbnailContent), int64(len(thumbnailContent)))
+	if err != nil {
+		_ = s.storage.Delete(ctx, key)
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	file.FileURI = fileURI
+	file.FileThumbnailURI = fileThumbnailURI
+
+	err = s.repo.Create(ctx, file)
+	if err != nil {
+		_ = s.storage.Delete(ctx, key)
+		_ = s.storage.Delete(ctx, thumbnailKey)
+		if domain.IsRequestError(err) {
+			return nil, err
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := &dto.UploadFileResponse{
+		FileID:           strconv.Itoa(file.ID),
+		FileURI:          file.FileURI,
+		FileThumbnailURI: file.FileThumbnailURI,
+	}
+
+	return res, nil
+}
+
+// GetFile implements contracts.FileService.
+func (s *fileService) GetFile(ctx context.Context, fileID string) (*entity.File, error) {
+	id, err := strconv.Atoi(fileID)
+	if err != nil {
+		return nil, domain.ErrFileNotFound
+	}
+
+	file, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFileNotFound
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return file, nil
+}
+
+// GetOwnedFile implements contracts.FileService.
+func (s *fileService) GetOwnedFile(ctx context.Context, userID int, fileID string) (*entity.File, error) {
+	file, err := s.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !file.UserID.Valid || int(file.UserID.Int32) != userID {
+		return nil, domain.ErrFileNotOwned
+	}
+
+	return file, nil
+}
+
+// GetPurchaseFile returns a file uploaded by a guest for the purchase
+func (s *fileService) GetPurchaseFile(ctx context.Context, purchaseID int, fileID string) (*entity.File, error) {
+	file, err := s.GetFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.UserID.Valid || !file.PurchaseID.Valid || int(file.PurchaseID.Int32) != purchaseID {
+		return nil, domain.ErrFileNotOfPurchase
+	}
+
+	return file, nil
+}
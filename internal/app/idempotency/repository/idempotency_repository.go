@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+)
+
+type idempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdempotencyRepository(db *sqlx.DB) contracts.IdempotencyRepository {
+	return &idempotencyRepository{db}
+}
+
+// Claim implements contracts.IdempotencyRepository.
+func (r *idempotencyRepository) Claim(ctx context.Context, key *entity.IdempotencyKey, ttl time.Duration) (bool, error) {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		INSERT INTO idempotency_keys (idempotency_key, scope, request_hash)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (idempotency_key, scope) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = NULL,
+			content_type = '',
+			response_body = NULL,
+			created_at = CURRENT_TIMESTAMP,
+			completed_at = NULL
+		WHERE idempotency_keys.created_at < CURRENT_TIMESTAMP - $4 * INTERVAL '1 second'
+	`, key.Key, key.Scope, key.RequestHash, ttl.Seconds())
+	if err != nil {
+		return false, database.MapError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// FindByKey implements contracts.IdempotencyRepository.
+func (r *idempotencyRepository) FindByKey(ctx context.Context, key, scope string) (entity.IdempotencyKey, error) {
+	var idempotencyKey entity.IdempotencyKey
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &idempotencyKey, "SELECT * FROM idempotency_keys WHERE idempotency_key = $1 AND scope = $2", key, scope)
+	return idempotencyKey, err
+}
+
+// Complete implements contracts.IdempotencyRepository.
+func (r *idempotencyRepository) Complete(ctx context.Context, key *entity.IdempotencyKey) error {
+	_, err := sqlx.NamedExecContext(ctx, database.Conn(ctx, r.db), `
+		UPDATE idempotency_keys
+		SET status_code = :status_code, content_type = :content_type, response_body = :response_body, completed_at = CURRENT_TIMESTAMP
+		WHERE idempotency_key = :idempotency_key AND scope = :scope
+	`, key)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+// Release implements contracts.IdempotencyRepository.
+func (r *idempotencyRepository) Release(ctx context.Context, key, scope string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM idempotency_keys WHERE idempotency_key = $1 AND scope = $2 AND completed_at IS NULL", key, scope)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+// DeleteExpired implements contracts.IdempotencyRepository.
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, ttl time.Duration) (int64, error) {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM idempotency_keys WHERE created_at < CURRENT_TIMESTAMP - $1 * INTERVAL '1 second'", ttl.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
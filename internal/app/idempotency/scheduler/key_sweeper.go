@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+// StartKeySweeper periodically deletes idempotency keys older than ttl, which
+// can't be replayed any more. It is safe to run on every replica.
+func StartKeySweeper(ctx context.Context, repository contracts.IdempotencyRepository, ttl time.Duration, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := repository.DeleteExpired(ctx, ttl)
+				if err != nil {
+					log.Error(log.LogInfo{
+						"error": err.Error(),
+					}, "[SCHEDULER][KeySweeper] failed to delete expired idempotency keys")
+					continue
+				}
+
+				if deleted > 0 {
+					log.Info(log.LogInfo{
+						"deleted": deleted,
+					}, "[SCHEDULER][KeySweeper] deleted expired idempotency keys")
+				}
+			}
+		}
+	}()
+}
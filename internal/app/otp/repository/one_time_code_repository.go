@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+)
+
+type oneTimeCodeRepository struct {
+	db *sqlx.DB
+}
+
+func NewOneTimeCodeRepository(db *sqlx.DB) contracts.OneTimeCodeRepository {
+	return &oneTimeCodeRepository{db}
+}
+
+// Create implements contracts.OneTimeCodeRepository.
+func (r *oneTimeCodeRepository) Create(ctx context.Context, code *entity.OneTimeCode, ttl time.Duration) error {
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx, `
+		INSERT INTO one_time_codes (user_id, purpose, target, code_hash, expires_at)
+		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + $5 * INTERVAL '1 second')
+		RETURNING id, expires_at, created_at
+	`, code.UserID, code.Purpose, code.Target, code.CodeHash, ttl.Seconds()).Scan(&code.ID, &code.ExpiresAt, &code.CreatedAt)
+	return database.MapError(err)
+}
+
+// IssuedWithin implements contracts.OneTimeCodeRepository.
+func (r *oneTimeCodeRepository) IssuedWithin(ctx context.Context, userID int, purpose string, interval time.Duration) (bool, error) {
+	var issued bool
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &issued, `
+		SELECT EXISTS (
+			SELECT 1 FROM one_time_codes
+			WHERE user_id = $1 AND purpose = $2 AND created_at > CURRENT_TIMESTAMP - $3 * INTERVAL '1 second'
+		)
+	`, userID, purpose, interval.Seconds())
+	return issued, err
+}
+
+// LockActive implements contracts.OneTimeCodeRepository.
+func (r *oneTimeCodeRepository) LockActive(ctx context.Context, userID int, purpose string, target string) (*entity.OneTimeCode, error) {
+	var code entity.OneTimeCode
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &code, `
+		SELECT * FROM one_time_codes
+		WHERE user_id = $1 AND purpose = $2 AND target = $3 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY id DESC
+		LIMIT 1
+		FOR UPDATE
+	`, userID, purpose, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &code, nil
+}
+
+// IncrementAttempts implements contracts.OneTimeCodeRepository.
+func (r *oneTimeCodeRepository) IncrementAttempts(ctx context.Context, id int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE one_time_codes SET attempts = attempts + 1 WHERE id = $1", id)
+	return database.MapError(err)
+}
+
+// MarkUsed implements contracts.OneTimeCodeRepository.
+func (r *oneTimeCodeRepository) MarkUsed(ctx context.Context, id int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE one_time_codes SET used_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	return database.MapError(err)
+}
+
+// Invalidate implements contracts.OneTimeCodeRepository.
+func (r *oneTimeCodeRepository) Invalidate(ctx context.Context, userID int, purpose string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE one_time_codes SET used_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL",
+		userID, purpose,
+	)
+	return database.MapError(err)
+}
+
+// contactColumns are the users columns holding the contact a verification
+// purpose is about
+var contactColumns = map[string]string{
+	entity.OneTimeCodeVerifyEmail: "email",
+	entity.OneTimeCodeVerifyPhone: "phone",
+}
+
+// ReleaseUnverifiedContact implements contracts.OneTimeCodeRepository.
+func (r *oneTimeCodeRepository) ReleaseUnverifiedContact(ctx context.Context, purpose string, target string) error {
+	column, ok := contactColumns[purpose]
+	if !ok {
+		return fmt.Errorf("no contact to release for one-time code purpose %q", purpose)
+	}
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, fmt.Sprintf(`
+		UPDATE users SET %[1]s = NULL
+		WHERE %[1]s = $1 AND %[1]s_verified_at IS NULL
+		AND COALESCE((
+			SELECT MIN(c.expires_at) FROM one_time_codes c
+			WHERE c.user_id = users.id AND c.purpose = $2 AND c.target = $1
+		), CURRENT_TIMESTAMP) <= CURRENT_TIMESTAMP
+	`, column), target, purpose)
+	return database.MapError(err)
+}
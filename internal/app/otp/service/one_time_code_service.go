@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/bcrypt"
+)
+
+const (
+	// codeDigits is the length of a code
+	codeDigits = 6
+	// maxAttempts is how many wrong guesses a code survives
+	maxAttempts = 5
+	// resendInterval is how long a user waits before another code is sent
+	resendInterval = time.Minute
+)
+
+// messages are the subject and body of the notification for every purpose,
+// the body is formatted with the code and its lifetime in minutes
+var messages = map[string][2]string{
+	entity.OneTimeCodePasswordReset: {
+		"Reset your Tutuplapak password",
+		"Your Tutuplapak password reset code is %s. It expires in %d minutes. If you didn't ask to reset your password, you can ignore this message.",
+	},
+	entity.OneTimeCodeVerifyEmail: {
+		"Verify your Tutuplapak email",
+		"Your Tutuplapak verification code is %s. It expires in %d minutes.",
+	},
+	entity.OneTimeCodeVerifyPhone: {
+		"",
+		"Your Tutuplapak verification code is %s. It expires in %d minutes.",
+	},
+}
+
+type oneTimeCodeService struct {
+	repo     contracts.OneTimeCodeRepository
+	uow      contracts.UnitOfWork
+	notifier contracts.Notifier
+	bcrypt   bcrypt.BcryptInterface
+	ttl      time.Duration
+}
+
+func NewOneTimeCodeService(
+	repo contracts.OneTimeCodeRepository,
+	uow contracts.UnitOfWork,
+	notifier contracts.Notifier,
+	bcrypt bcrypt.BcryptInterface,
+	ttl time.Duration,
+) contracts.OneTimeCodeService {
+	return &oneTimeCodeService{
+		repo,
+		uow,
+		notifier,
+		bcrypt,
+		ttl,
+	}
+}
+
+// Issue implements contracts.OneTimeCodeService.
+func (s *oneTimeCodeService) Issue(ctx context.Context, userID int, purpose string, channel string, target string) error {
+	message, ok := messages[purpose]
+	if !ok {
+		return fiber.NewError(fiber.StatusInternalServerError, "unknown one-time code purpose: "+purpose)
+	}
+
+	code, err := generateCode()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	codeHash, err := s.bcrypt.Hash(code)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		issued, err := s.repo.IssuedWithin(ctx, userID, purpose, resendInterval)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if issued {
+			return domain.ErrOneTimeCodeTooSoon
+		}
+
+		err = s.repo.Invalidate(ctx, userID, purpose)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		err = s.repo.Create(ctx, &entity.OneTimeCode{
+			UserID:   userID,
+			Purpose:  purpose,
+			Target:   target,
+			CodeHash: codeHash,
+		}, s.ttl)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		// Sent last, so the code is dropped again when it can't be delivered
+		err = s.notifier.Send(ctx, &dto.Notification{
+			Channel: channel,
+			To:      target,
+			Subject: message[0],
+			Body:    fmt.Sprintf(message[1], code, int(s.ttl.Minutes())),
+		})
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return nil
+	})
+}
+
+// Verify implements contracts.OneTimeCodeService.
+func (s *oneTimeCodeService) Verify(ctx context.Context, userID int, purpose string, target string, code string) error {
+	wrong := false
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		oneTimeCode, err := s.repo.LockActive(ctx, userID, purpose, target)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrInvalidOneTimeCode
+			}
+
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if oneTimeCode.Attempts >= maxAttempts {
+			return domain.ErrInvalidOneTimeCode
+		}
+
+		// The attempt has to be committed, so the error is only returned once
+		// the transaction is done
+		if !s.bcrypt.Compare(code, oneTimeCode.CodeHash) {
+			wrong = true
+
+			err := s.repo.IncrementAttempts(ctx, oneTimeCode.ID)
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+
+			return nil
+		}
+
+		err = s.repo.MarkUsed(ctx, oneTimeCode.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if wrong {
+		return domain.ErrInvalidOneTimeCode
+	}
+
+	return nil
+}
+
+// ReleaseUnverified implements contracts.OneTimeCodeService.
+func (s *oneTimeCodeService) ReleaseUnverified(ctx context.Context, purpose string, target string) error {
+	err := s.repo.ReleaseUnverifiedContact(ctx, purpose, target)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func generateCode() (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(codeDigits), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// fakeUnitOfWork runs fn right away and counts how its transactions ended
+type fakeUnitOfWork struct {
+	committed  int
+	rolledBack int
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		u.rolledBack++
+		return err
+	}
+
+	u.committed++
+	return nil
+}
+
+type fakeOneTimeCodeRepository struct {
+	codes  map[int]*entity.OneTimeCode
+	nextID int
+}
+
+func (r *fakeOneTimeCodeRepository) Create(_ context.Context, code *entity.OneTimeCode, ttl time.Duration) error {
+	r.nextID++
+	code.ID = r.nextID
+	code.CreatedAt = time.Now()
+	code.ExpiresAt = code.CreatedAt.Add(ttl)
+
+	copied := *code
+	r.codes[code.ID] = &copied
+	return nil
+}
+
+func (r *fakeOneTimeCodeRepository) IssuedWithin(_ context.Context, userID int, purpose string, interval time.Duration) (bool, error) {
+	for _, code := range r.codes {
+		if code.UserID == userID && code.Purpose == purpose && code.CreatedAt.After(time.Now().Add(-interval)) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (r *fakeOneTimeCodeRepository) LockActive(_ context.Context, userID int, purpose string, target string) (*entity.OneTimeCode, error) {
+	var latest *entity.OneTimeCode
+	for _, code := range r.codes {
+		if code.UserID != userID || code.Purpose != purpose || code.Target != target || code.UsedAt.Valid || !code.ExpiresAt.After(time.Now()) {
+			continue
+		}
+
+		if latest == nil || code.ID > latest.ID {
+			latest = code
+		}
+	}
+
+	if latest == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	copied := *latest
+	return &copied, nil
+}
+
+func (r *fakeOneTimeCodeRepository) IncrementAttempts(_ context.Context, id int) error {
+	r.codes[id].Attempts++
+	return nil
+}
+
+func (r *fakeOneTimeCodeRepository) MarkUsed(_ context.Context, id int) error {
+	r.codes[id].UsedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *fakeOneTimeCodeRepository) Invalidate(ctx context.Context, userID int, purpose string) error {
+	for _, code := range r.codes {
+		if code.UserID == userID && code.Purpose == purpose && !code.UsedAt.Valid {
+			r.MarkUsed(ctx, code.ID)
+		}
+	}
+
+	return nil
+}
+
+func (r *fakeOneTimeCodeRepository) ReleaseUnverifiedContact(context.Context, string, string) error {
+	return nil
+}
+
+// fakeNotifier keeps the notifications it was asked to send
+type fakeNotifier struct {
+	sent []dto.Notification
+	err  error
+}
+
+func (n *fakeNotifier) Send(_ context.Context, notification *dto.Notification) error {
+	if n.err != nil {
+		return n.err
+	}
+
+	n.sent = append(n.sent, *notification)
+	return nil
+}
+
+var sentCode = regexp.MustCompile(`\d{6}`)
+
+// lastCode returns the code in the latest notification
+func (n *fakeNotifier) lastCode(t *testing.T) string {
+	t.Helper()
+
+	if len(n.sent) == 0 {
+		t.Fatalf("no code was sent")
+	}
+
+	code := sentCode.FindString(n.sent[len(n.sent)-1].Body)
+	if code == "" {
+		t.Fatalf("notification %q has no code", n.sent[len(n.sent)-1].Body)
+	}
+
+	return code
+}
+
+// fakeBcrypt skips the cost of real hashing
+type fakeBcrypt struct{}
+
+func (fakeBcrypt) Hash(plain string) (string, error)    { return "hashed:" + plain, nil }
+func (fakeBcrypt) Compare(password, hashed string) bool { return "hashed:"+password == hashed }
+
+type oneTimeCodeFixture struct {
+	service  *oneTimeCodeService
+	repo     *fakeOneTimeCodeRepository
+	notifier *fakeNotifier
+	uow      *fakeUnitOfWork
+}
+
+const (
+	testUserID = 1
+	testTarget = "buyer@example.com"
+)
+
+func newOneTimeCodeFixture() *oneTimeCodeFixture {
+	f := &oneTimeCodeFixture{
+		repo:     &fakeOneTimeCodeRepository{codes: map[int]*entity.OneTimeCode{}},
+		notifier: &fakeNotifier{},
+		uow:      &fakeUnitOfWork{},
+	}
+
+	f.service = NewOneTimeCodeService(f.repo, f.uow, f.notifier, fakeBcrypt{}, 10*time.Minute).(*oneTimeCodeService)
+	return f
+}
+
+func (f *oneTimeCodeFixture) issue() error {
+	return f.service.Issue(context.Background(), testUserID, entity.OneTimeCodeVerifyEmail, dto.NotificationChannelEmail, testTarget)
+}
+
+func (f *oneTimeCodeFixture) verify(code string) error {
+	return f.service.Verify(context.Background(), testUserID, entity.OneTimeCodeVerifyEmail, testTarget, code)
+}
+
+// wrongCode differs from code in its first digit
+func wrongCode(code string) string {
+	if code[0] == '0' {
+		return "1" + code[1:]
+	}
+
+	return "0" + code[1:]
+}
+
+func TestVerifyAcceptsACodeOnce(t *testing.T) {
+	f := newOneTimeCodeFixture()
+	if err := f.issue(); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	code := f.notifier.lastCode(t)
+	if got := f.notifier.sent[0].To; got != testTarget {
+		t.Errorf("code sent to %s, want %s", got, testTarget)
+	}
+
+	if err := f.verify(code); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	if err := f.verify(code); !errors.Is(err, domain.ErrInvalidOneTimeCode) {
+		t.Errorf("Verify() of a used code error = %v, want %v", err, domain.ErrInvalidOneTimeCode)
+	}
+}
+
+func TestVerifyAttemptLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		wrongGuesses  int
+		wantAttempts  int
+		wantCodeValid bool
+	}{
+		{name: "no wrong guess", wantCodeValid: true},
+		{name: "one wrong guess", wrongGuesses: 1, wantAttempts: 1, wantCodeValid: true},
+		{name: "one guess short of the limit", wrongGuesses: maxAttempts - 1, wantAttempts: maxAttempts - 1, wantCodeValid: true},
+		{name: "limit reached", wrongGuesses: maxAttempts, wantAttempts: maxAttempts},
+		{name: "guesses past the limit aren't counted", wrongGuesses: maxAttempts + 2, wantAttempts: maxAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOneTimeCodeFixture()
+			if err := f.issue(); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			code := f.notifier.lastCode(t)
+
+			for i := 0; i < tt.wrongGuesses; i++ {
+				if err := f.verify(wrongCode(code)); !errors.Is(err, domain.ErrInvalidOneTimeCode) {
+					t.Fatalf("Verify() of a wrong code error = %v, want %v", err, domain.ErrInvalidOneTimeCode)
+				}
+			}
+
+			if got := f.repo.codes[1].Attempts; got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+
+			err := f.verify(code)
+			if tt.wantCodeValid && err != nil {
+				t.Errorf("Verify() of the right code error = %v", err)
+			}
+
+			if !tt.wantCodeValid && !errors.Is(err, domain.ErrInvalidOneTimeCode) {
+				t.Errorf("Verify() of the right code after the limit error = %v, want %v", err, domain.ErrInvalidOneTimeCode)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		verify func(f *oneTimeCodeFixture, code string) error
+	}{
+		{
+			name: "expired code",
+			verify: func(f *oneTimeCodeFixture, code string) error {
+				f.repo.codes[1].ExpiresAt = time.Now().Add(-time.Second)
+				return f.verify(code)
+			},
+		},
+		{
+			name: "code for another contact",
+			verify: func(f *oneTimeCodeFixture, code string) error {
+				return f.service.Verify(context.Background(), testUserID, entity.OneTimeCodeVerifyEmail, "other@example.com", code)
+			},
+		},
+		{
+			name: "code for another purpose",
+			verify: func(f *oneTimeCodeFixture, code string) error {
+				return f.service.Verify(context.Background(), testUserID, entity.OneTimeCodePasswordReset, testTarget, code)
+			},
+		},
+		{
+			name: "code of another user",
+			verify: func(f *oneTimeCodeFixture, code string) error {
+				return f.service.Verify(context.Background(), testUserID+1, entity.OneTimeCodeVerifyEmail, testTarget, code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newOneTimeCodeFixture()
+			if err := f.issue(); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			err := tt.verify(f, f.notifier.lastCode(t))
+			if !errors.Is(err, domain.ErrInvalidOneTimeCode) {
+				t.Errorf("Verify() error = %v, want %v", err, domain.ErrInvalidOneTimeCode)
+			}
+		})
+	}
+}
+
+func TestIssueThrottlesAndReplacesCodes(t *testing.T) {
+	f := newOneTimeCodeFixture()
+	if err := f.issue(); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	first := f.notifier.lastCode(t)
+
+	if err := f.issue(); !errors.Is(err, domain.ErrOneTimeCodeTooSoon) {
+		t.Fatalf("Issue() right after the first error = %v, want %v", err, domain.ErrOneTimeCodeTooSoon)
+	}
+
+	if len(f.notifier.sent) != 1 {
+		t.Errorf("%d codes sent, want the throttled one held back", len(f.notifier.sent))
+	}
+
+	f.repo.codes[1].CreatedAt = time.Now().Add(-resendInterval)
+	if err := f.issue(); err != nil {
+		t.Fatalf("Issue() after the resend interval error = %v", err)
+	}
+	second := f.notifier.lastCode(t)
+
+	if first != second {
+		if err := f.verify(first); !errors.Is(err, domain.ErrInvalidOneTimeCode) {
+			t.Errorf("Verify() of the replaced code error = %v, want %v", err, domain.ErrInvalidOneTimeCode)
+		}
+	}
+
+	if err := f.verify(second); err != nil {
+		t.Errorf("Verify() of the new code error = %v", err)
+	}
+}
+
+func TestIssueFailsWhenTheCodeCantBeSent(t *testing.T) {
+	f := newOneTimeCodeFixture()
+	f.notifier.err = errors.New("smtp is down")
+
+	if err := f.issue(); err == nil {
+		t.Fatalf("Issue() error = nil, want the error of the notifier")
+	}
+
+	if f.uow.rolledBack != 1 || f.uow.committed != 0 {
+		t.Errorf("transactions committed %d rolled back %d, want the code rolled back", f.uow.committed, f.uow.rolledBack)
+	}
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+)
+
+type productController struct {
+	service contracts.ProductService
+}
+
+func InitProductController(router fiber.Router, service contracts.ProductService, middleware *middlewares.Middleware) {
+	controller := &productController{
+		service,
+	}
+
+	productRouter := router.Group("/product")
+
+	productRouter.Get("/", controller.getProducts)
+	productRouter.Post("/", middleware.RequireAuth(), controller.createProduct)
+	productRouter.Put("/:productId", middleware.RequireAuth(), controller.updateProduct)
+	productRouter.Delete("/:productId", middleware.RequireAuth(), controller.deleteProduct)
+}
+
+func (c *productController) getProducts(ctx *fiber.Ctx) error {
+	var query dto.GetProductsQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.GetProducts(ctx.Context(), &query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *productController) createProduct(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var req dto.CreateProductRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.CreateProduct(ctx.Context(), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(res)
+}
+
+func (c *productController) updateProduct(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var req dto.UpdateProductRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.UpdateProduct(ctx.Context(), userID, ctx.Params("productId"), &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *productController) deleteProduct(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("claims").(jwt.Claims).UserID
+
+	err := c.service.DeleteProduct(ctx.Context(), userID, ctx.Params("productId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
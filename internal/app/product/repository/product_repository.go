@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+)
+
+type productRepository struct {
+	db *sqlx.DB
+}
+
+func NewProductRepository(db *sqlx.DB) contracts.ProductRepository {
+	return &productRepository{db}
+}
+
+// FindAll implements contracts.ProductRepository.
+func (r *productRepository) FindAll(ctx context.Context, query *dto.GetProductsQuery) ([]entity.Product, int, error) {
+	conditions := []string{}
+	args := []any{}
+
+	addCondition := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query.ProductID != "" {
+		addCondition("id = $%d", query.ProductID)
+	}
+	if query.Category != 0 {
+		addCondition("category = $%d", query.Category)
+	}
+	if query.SKU != "" {
+		addCondition("sku = $%d", query.SKU)
+	}
+	if query.UserID != 0 {
+		addCondition("user_id = $%d", query.UserID)
+	}
+	if !query.MinPrice.IsZero() {
+		addCondition("price >= $%d", query.MinPrice)
+	}
+	if !query.MaxPrice.IsZero() {
+		addCondition("price <= $%d", query.MaxPrice)
+	}
+	if query.InStock {
+		conditions = append(conditions, "qty > 0")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &total, "SELECT COUNT(*) FROM products "+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orderBy := "created_at DESC, id DESC"
+	switch query.SortBy {
+	case "oldest":
+		orderBy = "created_at ASC, id ASC"
+	case "cheapest":
+		orderBy = "price ASC, id ASC"
+	case "expensive":
+		orderBy = "price DESC, id DESC"
+	}
+
+	args = append(args, query.Limit, query.Offset)
+	stmt := fmt.Sprintf(
+		"SELECT * FROM products %s ORDER BY %s LIMIT $%d OFFSET $%d",
+		where, orderBy, len(args)-1, len(args),
+	)
+
+	products := []entity.Product{}
+	err = sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &products, stmt, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// FindByID implements contracts.ProductRepository.
+func (r *productRepository) FindByID(ctx context.Context, id int) (*entity.Product, error) {
+	var product entity.Product
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &product, "SELECT * FROM products WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// Create implements contracts.ProductRepository.
+func (r *productRepository) Create(ctx context.Context, product *entity.Product) error {
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), `
+		INSERT INTO products (name, category, qty, price, sku, file_id, file_url, file_thumbnail_url, user_id)
+		VALUES (:name, :category, :qty, :price, :sku, :file_id, :file_url, :file_thumbnail_url, :user_id)
+		RETURNING id, created_at, updated_at
+	`, product)
+	if err != nil {
+		return database.MapError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return database.MapError(err)
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&product.ID, &product.CreatedAt, &product.UpdatedAt)
+}
+
+// Update implements contracts.ProductRepository.
+func (r *productRepository) Update(ctx context.Context, product *entity.Product) error {
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), `
+		UPDATE products
+		SET name = :name, category = :category, qty = :qty, price = :price, sku = :sku,
+			file_id = :file_id, file_url = :file_url, file_thumbnail_url = :file_thumbnail_url,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = :id
+		RETURNING updated_at
+	`, product)
+	if err != nil {
+		return database.MapError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return database.MapError(err)
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&product.UpdatedAt)
+}
+
+// Delete implements contracts.ProductRepository.
+func (r *productRepository) Delete(ctx context.Context, id int) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM products WHERE id = $1", id)
+	if err != nil {
+		return database.MapError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+type productService struct {
+	repo        contracts.ProductRepository
+	fileService contracts.FileService
+	validator   validator.ValidatorInterface
+}
+
+func NewProductService(repo contracts.ProductRepository, fileService contracts.FileService, validator validator.ValidatorInterface) contracts.ProductService {
+	return &productService{
+		repo,
+		fileService,
+		validator,
+	}
+}
+
+// GetProducts implements contracts.ProductService.
+func (s *productService) GetProducts(ctx context.Context, query *dto.GetProductsQuery) (*dto.PaginatedResponse[dto.ProductResponse], error) {
+	valErr := s.validator.Validate(query)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	query.Normalize()
+
+	products, total, err := s.repo.FindAll(ctx, query)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	data := make([]dto.ProductResponse, 0, len(products))
+	for _, product := range products {
+		data = append(data, toProductResponse(&product))
+	}
+
+	res := &dto.PaginatedResponse[dto.ProductResponse]{
+		Data: data,
+		Meta: dto.PaginationMeta{
+			Limit:  query.Limit,
+			Offset: query.Offset,
+			Total:  total,
+		},
+	}
+
+	return res, nil
+}
+
+// CreateProduct implements contracts.ProductService.
+func (s *productService) CreateProduct(ctx context.Context, userID int, req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	file, err := s.fileService.GetOwnedFile(ctx, userID, req.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	product := &entity.Product{
+		Name:             req.Name,
+		Category:         req.Category,
+		Quantity:         req.Qty,
+		Price:            req.Price,
+		SKU:              req.SKU,
+		FileID:           strconv.Itoa(file.ID),
+		FileURL:          file.FileURI,
+		FileThumbnailURL: file.FileThumbnailURI,
+		UserID:           userID,
+	}
+
+	err = s.repo.Create(ctx, product)
+	if err != nil {
+		if domain.IsRequestError(err) {
+			return nil, err
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := toProductResponse(product)
+
+	return &res, nil
+}
+
+// UpdateProduct implements contracts.ProductService.
+func (s *productService) UpdateProduct(ctx context.Context, userID int, productID string, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	product, err := s.findOwnedProduct(ctx, userID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Name = req.Name
+	product.Category = req.Category
+	product.Quantity = req.Qty
+	product.Price = req.Price
+	product.SKU = req.SKU
+
+	if req.FileID != product.FileID {
+		file, err := s.fileService.GetOwnedFile(ctx, userID, req.FileID)
+		if err != nil {
+			return nil, err
+		}
+
+		product.FileID = strconv.Itoa(file.ID)
+		product.FileURL = file.FileURI
+		product.FileThumbnailURL = file.FileThumbnailURI
+	}
+
+	err = s.repo.Update(ctx, product)
+	if err != nil {
+		if domain.IsRequestError(err) {
+			return nil, err
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := toProductResponse(product)
+
+	return &res, nil
+}
+
+// DeleteProduct implements contracts.ProductService.
+func (s *productService) DeleteProduct(ctx context.Context, userID int, productID string) error {
+	product, err := s.findOwnedProduct(ctx, userID, productID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.Delete(ctx, product.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+
+		if domain.IsRequestError(err) {
+			return err
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// findOwnedProduct looks up a product and makes sure it belongs to the given user
+func (s *productService) findOwnedProduct(ctx context.Context, userID int, productID string) (*entity.Product, error) {
+	id, err := strconv.Atoi(productID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "product not found")
+	}
+
+	product, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if product.UserID != userID {
+		return nil, fiber.NewError(fiber.StatusForbidden, "product does not belong to user")
+	}
+
+	return product, nil
+}
+
+func toProductResponse(product *entity.Product) dto.ProductResponse {
+	return dto.ProductResponse{
+		ProductID:        strconv.Itoa(product.ID),
+		Name:             product.Name,
+		Category:         product.Category,
+		Qty:              product.Quantity,
+		ReservedQty:      product.ReservedQuantity,
+		Price:            product.Price,
+		Currency:         product.Price.Currency(),
+		SKU:              product.SKU,
+		FileID:           product.FileID,
+		FileURI:          product.FileURL,
+		FileThumbnailURI: product.FileThumbnailURL,
+		CreatedAt:        product.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        product.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -4,24 +4,28 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
 )
 
 type purchaseController struct {
 	purchaseService contracts.PurchaseService
 }
 
-func InitPurchaseController(router fiber.Router, purchaseService contracts.PurchaseService) {
+func InitPurchaseController(router fiber.Router, purchaseService contracts.PurchaseService, middleware *middlewares.Middleware) {
 	controller := purchaseController{
 		purchaseService: purchaseService,
 	}
 
-	// jwt := jwt.Jwt
-
-	// middleware := middlewares.NewMiddleware(jwt)
-
-	purchaseRoute := router.Group("/v1/purchase")
-	purchaseRoute.Post("/", controller.Purchase)
-	purchaseRoute.Post("/:purchaseId", controller.UploadPayment)
+	purchaseRoute := router.Group("/purchase")
+	purchaseRoute.Get("/", middleware.RequireAuth(), controller.GetPurchases)
+	purchaseRoute.Post("/", middleware.OptionalAuth(), middleware.Idempotency(), controller.Purchase)
+	purchaseRoute.Get("/:purchaseId", middleware.OptionalAuth(), controller.GetPurchase)
+	purchaseRoute.Post("/:purchaseId", middleware.OptionalAuth(), middleware.Idempotency(), controller.UploadPayment)
+	purchaseRoute.Post("/:purchaseId/cancel", middleware.OptionalAuth(), controller.CancelPurchase)
+	purchaseRoute.Post("/:purchaseId/complete", middleware.OptionalAuth(), controller.CompletePurchase)
+	purchaseRoute.Put("/:purchaseId/status", middleware.RequireAuth(), controller.UpdateStatus)
+	purchaseRoute.Get("/:purchaseId/events", middleware.OptionalAuth(), controller.GetEvents)
 }
 
 func (mc *purchaseController) Purchase(ctx *fiber.Ctx) error {
@@ -30,7 +34,7 @@ func (mc *purchaseController) Purchase(ctx *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	res, err := mc.purchaseService.Purchase(ctx.Context(), req)
+	res, err := mc.purchaseService.Purchase(ctx.Context(), optionalUserId(ctx), req)
 	if err != nil {
 		return err
 	}
@@ -38,6 +42,31 @@ func (mc *purchaseController) Purchase(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusCreated).JSON(res)
 }
 
+func (mc *purchaseController) GetPurchases(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var query dto.GetPurchasesQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := mc.purchaseService.GetPurchases(ctx.Context(), userId, &query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (mc *purchaseController) GetPurchase(ctx *fiber.Ctx) error {
+	res, err := mc.purchaseService.GetPurchase(ctx.Context(), optionalUserId(ctx), ctx.Params("purchaseId"), ctx.Get(middlewares.LookupTokenHeader))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
 func (mc *purchaseController) UploadPayment(ctx *fiber.Ctx) error {
 	var requestBody dto.UploadPaymentRequest
 	if err := ctx.BodyParser(&requestBody); err != nil {
@@ -46,11 +75,64 @@ func (mc *purchaseController) UploadPayment(ctx *fiber.Ctx) error {
 		})
 	}
 
-	purchaseId := ctx.Params("purchaseId")
-
-	err := mc.purchaseService.UploadPayment(ctx.Context(), requestBody, purchaseId)
+	err := mc.purchaseService.UploadPayment(ctx.Context(), optionalUserId(ctx), ctx.Params("purchaseId"), ctx.Get(middlewares.LookupTokenHeader), requestBody)
 	if err != nil {
 		return err
 	}
 	return ctx.SendStatus(fiber.StatusCreated)
 }
+
+func (mc *purchaseController) CancelPurchase(ctx *fiber.Ctx) error {
+	var req dto.CancelPurchaseRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := mc.purchaseService.CancelPurchase(ctx.Context(), optionalUserId(ctx), ctx.Params("purchaseId"), ctx.Get(middlewares.LookupTokenHeader), req)
+	if err != nil {
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (mc *purchaseController) CompletePurchase(ctx *fiber.Ctx) error {
+	err := mc.purchaseService.CompletePurchase(ctx.Context(), optionalUserId(ctx), ctx.Params("purchaseId"), ctx.Get(middlewares.LookupTokenHeader))
+	if err != nil {
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (mc *purchaseController) UpdateStatus(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var req dto.UpdatePurchaseStatusRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := mc.purchaseService.UpdateStatus(ctx.Context(), userId, ctx.Params("purchaseId"), req)
+	if err != nil {
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (mc *purchaseController) GetEvents(ctx *fiber.Ctx) error {
+	res, err := mc.purchaseService.GetEvents(ctx.Context(), optionalUserId(ctx), ctx.Params("purchaseId"), ctx.Get(middlewares.LookupTokenHeader))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+// optionalUserId returns the user behind middleware.OptionalAuth, or 0 for guests
+func optionalUserId(ctx *fiber.Ctx) int {
+	claims, ok := ctx.Locals("claims").(jwt.Claims)
+	if !ok {
+		return 0
+	}
+
+	return claims.UserID
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+)
+
+type sellerController struct {
+	purchaseService contracts.PurchaseService
+}
+
+func InitSellerController(router fiber.Router, purchaseService contracts.PurchaseService, middleware *middlewares.Middleware) {
+	controller := sellerController{
+		purchaseService: purchaseService,
+	}
+
+	sellerRoute := router.Group("/seller", middleware.RequireAuth())
+	sellerRoute.Get("/orders", controller.GetOrders)
+	sellerRoute.Post("/orders/:purchaseId/confirm", controller.ConfirmPayment)
+	sellerRoute.Post("/orders/:purchaseId/reject", controller.RejectPayment)
+	sellerRoute.Post("/orders/:purchaseId/refund", controller.RefundPayment)
+}
+
+func (sc *sellerController) GetOrders(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var query dto.GetSellerOrdersQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := sc.purchaseService.GetSellerOrders(ctx.Context(), userId, &query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (sc *sellerController) ConfirmPayment(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("claims").(jwt.Claims).UserID
+
+	err := sc.purchaseService.ConfirmPayment(ctx.Context(), userId, ctx.Params("purchaseId"))
+	if err != nil {
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (sc *sellerController) RejectPayment(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var req dto.RejectPaymentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := sc.purchaseService.RejectPayment(ctx.Context(), userId, ctx.Params("purchaseId"), req)
+	if err != nil {
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (sc *sellerController) RefundPayment(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var req dto.RefundPaymentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := sc.purchaseService.RefundPayment(ctx.Context(), userId, ctx.Params("purchaseId"), req)
+	if err != nil {
+		return err
+	}
+	return ctx.SendStatus(fiber.StatusOK)
+}
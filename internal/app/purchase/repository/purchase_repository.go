@@ -2,10 +2,17 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
 )
 
 type purchaseRepository struct {
@@ -18,37 +25,361 @@ func NewPurchaseRepository(db *sqlx.DB) contracts.PurchaseRepository {
 	}
 }
 
-func (r *purchaseRepository) CreatePurchase(ctx context.Context, purchasedItems []entity.PurchaseItem, senderName string, senderContactType string, senderContactDetail string) (int64, error) {
-	result, err := r.db.Exec("INSERT INTO purchase (purchased_items, sender_name, sender_contact_type, sender_contact_detail) VALUES ($1, $2, $3, $4) RETURNING id", purchasedItems, senderName, senderContactType, senderContactDetail)
+// CreatePurchase inserts the purchase and fills in its generated columns. The
+// stock reservation expires reservationTTL after the purchase is created.
+func (r *purchaseRepository) CreatePurchase(ctx context.Context, purchase *entity.Purchase, reservationTTL time.Duration) error {
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx, `
+		INSERT INTO purchase (buyer_id, purchased_items, sender_name, sender_contact_type, sender_contact_detail, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP + $7 * INTERVAL '1 second')
+		RETURNING id, created_at, updated_at, expires_at
+	`, purchase.BuyerID, purchase.PurchasedItems, purchase.SenderName, purchase.SenderContactType, purchase.SenderContactDetail, purchase.Status, reservationTTL.Seconds(),
+	).Scan(&purchase.ID, &purchase.CreatedAt, &purchase.UpdatedAt, &purchase.ExpiresAt)
 	if err != nil {
-		return 0, err
+		return database.MapError(err)
 	}
 
-	return result.LastInsertId()
+	return nil
+}
+
+// CreatePurchaseItems stores the items of a purchase as rows, so they can be
+// looked up by seller
+func (r *purchaseRepository) CreatePurchaseItems(ctx context.Context, purchaseId int, items []entity.PurchaseItem) error {
+	for _, item := range items {
+		item.PurchaseID = purchaseId
+		_, err := sqlx.NamedExecContext(ctx, database.Conn(ctx, r.db), `
+			INSERT INTO purchase_items (purchase_id, product_id, seller_id, name, category, qty, price, sku, file_id, file_url, file_thumbnail_url)
+			VALUES (:purchase_id, :product_id, :seller_id, :name, :category, :qty, :price, :sku, CAST(NULLIF(:file_id, '') AS INT), :file_url, :file_thumbnail_url)
+		`, item)
+		if err != nil {
+			return database.MapError(err)
+		}
+	}
+	return nil
 }
 
-func (r *purchaseRepository) DecreaseQuantity(ctx context.Context, productId int, quantity int) error {
-	_, err := r.db.ExecContext(ctx, "UPDATE products SET qty = qty - $1 WHERE id = $2", quantity, productId)
+// ReserveQuantity moves quantity from the available stock to the reserved
+// stock. It only succeeds when enough stock is available, otherwise
+// domain.ErrInsufficientStock is returned.
+func (r *purchaseRepository) ReserveQuantity(ctx context.Context, productId int, quantity int) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE products SET qty = qty - $1, reserved_qty = reserved_qty + $1 WHERE id = $2 AND qty >= $1", quantity, productId)
+	if err != nil {
+		return database.MapError(err)
+	}
+
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
+
+	if affected == 0 {
+		return domain.ErrInsufficientStock
+	}
+
+	return nil
+}
+
+// CommitReservedQuantity drops a reservation once the purchase is paid; the
+// stock has already been taken out of qty. domain.ErrReservationMismatch is
+// returned when less than quantity is reserved.
+func (r *purchaseRepository) CommitReservedQuantity(ctx context.Context, productId int, quantity int) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE products SET reserved_qty = reserved_qty - $1 WHERE id = $2 AND reserved_qty >= $1", quantity, productId)
+	if err != nil {
+		return database.MapError(err)
+	}
+
+	return expectReservation(result)
+}
+
+// ReleaseReservedQuantity gives reserved stock back to the available stock.
+// domain.ErrReservationMismatch is returned when less than quantity is
+// reserved.
+func (r *purchaseRepository) ReleaseReservedQuantity(ctx context.Context, productId int, quantity int) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE products SET qty = qty + $1, reserved_qty = reserved_qty - $1 WHERE id = $2 AND reserved_qty >= $1", quantity, productId)
+	if err != nil {
+		return database.MapError(err)
+	}
+
+	return expectReservation(result)
+}
+
+// expectReservation turns an update of the reserved stock that matched no row
+// into domain.ErrReservationMismatch, so the transaction is rolled back instead
+// of the counters drifting apart
+func expectReservation(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return domain.ErrReservationMismatch
+	}
+
+	return nil
+}
+
+// RestoreReservedQuantity reserves stock that was committed by a payment again,
+// for when the payment turns out to be invalid
+func (r *purchaseRepository) RestoreReservedQuantity(ctx context.Context, productId int, quantity int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE products SET reserved_qty = reserved_qty + $1 WHERE id = $2", quantity, productId)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+// RestockQuantity puts sold stock back on sale, for refunded purchases
+func (r *purchaseRepository) RestockQuantity(ctx context.Context, productId int, quantity int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE products SET qty = qty + $1 WHERE id = $2", quantity, productId)
+	if err != nil {
+		return database.MapError(err)
+	}
 	return nil
 }
 
 func (r *purchaseRepository) GetProductById(ctx context.Context, productId int) (entity.Product, error) {
 	var product entity.Product
-	err := r.db.GetContext(ctx, &product, "SELECT * FROM products WHERE id=$1", productId)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &product, "SELECT * FROM products WHERE id=$1", productId)
 	return product, err
 }
 
-func (r *purchaseRepository) GetSellerById(ctx context.Context, sellerId int) (entity.DummyUser, error) {
-	var seller entity.DummyUser
-	err := r.db.GetContext(ctx, &seller, "SELECT * FROM users WHERE id=$1", sellerId)
+func (r *purchaseRepository) GetSellerById(ctx context.Context, sellerId int) (entity.User, error) {
+	var seller entity.User
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &seller, "SELECT * FROM users WHERE id=$1", sellerId)
 	return seller, err
 }
 
 func (r *purchaseRepository) GetPurchaseById(ctx context.Context, purchaseId int) (entity.Purchase, error) {
 	var purchase entity.Purchase
-	err := r.db.GetContext(ctx, &purchase, "SELECT * FROM purchase WHERE id=$1", purchaseId)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &purchase, "SELECT * FROM purchase WHERE id=$1", purchaseId)
 	return purchase, err
 }
+
+// GetBuyerPurchases returns the purchases made by the buyer, newest first
+func (r *purchaseRepository) GetBuyerPurchases(ctx context.Context, buyerId int, query *dto.GetPurchasesQuery) ([]entity.Purchase, int, error) {
+	args := []any{buyerId}
+	where := "WHERE buyer_id = $1"
+
+	if query.Status != "" {
+		args = append(args, query.Status)
+		where += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &total, "SELECT COUNT(*) FROM purchase "+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, query.Limit, query.Offset)
+	purchases := []entity.Purchase{}
+	err = sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &purchases, fmt.Sprintf(
+		"SELECT * FROM purchase %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+		where, len(args)-1, len(args),
+	), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return purchases, total, nil
+}
+
+// LockPurchaseById reads the purchase and locks it until the transaction ends
+func (r *purchaseRepository) LockPurchaseById(ctx context.Context, purchaseId int) (entity.Purchase, error) {
+	var purchase entity.Purchase
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &purchase, "SELECT * FROM purchase WHERE id=$1 FOR UPDATE", purchaseId)
+	return purchase, err
+}
+
+// LockExpiredPurchases returns pending purchases whose reservation has expired
+// and that no seller holds an outstanding payment proof for. Shares the seller
+// rejected or refunded don't keep the purchase alive. Rows already locked by
+// another replica are skipped.
+func (r *purchaseRepository) LockExpiredPurchases(ctx context.Context, limit int) ([]entity.Purchase, error) {
+	purchases := []entity.Purchase{}
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &purchases, `
+		SELECT * FROM purchase
+		WHERE status = $1 AND expires_at <= CURRENT_TIMESTAMP
+		AND NOT EXISTS (
+			SELECT 1 FROM purchase_payments pp
+			WHERE pp.purchase_id = purchase.id AND pp.status IN ($2, $3)
+		)
+		ORDER BY expires_at
+		LIMIT $4
+		FOR UPDATE SKIP LOCKED
+	`, entity.PurchaseStatusPending, entity.PaymentStatusSubmitted, entity.PaymentStatusConfirmed, limit)
+	return purchases, err
+}
+
+func (r *purchaseRepository) UpdatePurchaseStatus(ctx context.Context, purchaseId int, status string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE purchase SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", status, purchaseId)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+// ExtendReservation lets the stock reservation of the purchase run for another
+// reservationTTL from now
+func (r *purchaseRepository) ExtendReservation(ctx context.Context, purchaseId int, reservationTTL time.Duration) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE purchase SET expires_at = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second', updated_at = CURRENT_TIMESTAMP WHERE id = $2", reservationTTL.Seconds(), purchaseId)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+func (r *purchaseRepository) CreatePurchaseEvent(ctx context.Context, event *entity.PurchaseEvent) error {
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx, `
+		INSERT INTO purchase_events (purchase_id, from_status, to_status, actor_user_id, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, event.PurchaseID, event.FromStatus, event.ToStatus, event.ActorUserID, event.Note,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+func (r *purchaseRepository) GetPurchaseEvents(ctx context.Context, purchaseId int) ([]entity.PurchaseEvent, error) {
+	events := []entity.PurchaseEvent{}
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &events, "SELECT * FROM purchase_events WHERE purchase_id=$1 ORDER BY created_at, id", purchaseId)
+	return events, err
+}
+
+func (r *purchaseRepository) CreatePurchasePayment(ctx context.Context, payment *entity.PurchasePayment) error {
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), `
+		INSERT INTO purchase_payments (purchase_id, seller_id, bank_account_name, bank_account_holder, bank_account_number, subtotal, discount, voucher_code, total_price, status)
+		VALUES (:purchase_id, :seller_id, :bank_account_name, :bank_account_holder, :bank_account_number, :subtotal, :discount, :voucher_code, :total_price, :status)
+		RETURNING id, created_at, updated_at
+	`, payment)
+	if err != nil {
+		return database.MapError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return database.MapError(err)
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+}
+
+func (r *purchaseRepository) GetPurchasePayments(ctx context.Context, purchaseId int) ([]entity.PurchasePayment, error) {
+	payments := []entity.PurchasePayment{}
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &payments, "SELECT * FROM purchase_payments WHERE purchase_id=$1 ORDER BY id", purchaseId)
+	return payments, err
+}
+
+func (r *purchaseRepository) GetPaymentsByPurchaseIds(ctx context.Context, purchaseIds []int) ([]entity.PurchasePayment, error) {
+	payments := []entity.PurchasePayment{}
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &payments, "SELECT * FROM purchase_payments WHERE purchase_id = ANY($1) ORDER BY id", purchaseIds)
+	return payments, err
+}
+
+func (r *purchaseRepository) GetPaymentProofsByPurchaseIds(ctx context.Context, purchaseIds []int) ([]entity.PurchasePaymentProof, error) {
+	proofs := []entity.PurchasePaymentProof{}
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &proofs, `
+		SELECT ppp.* FROM purchase_payment_proofs ppp
+		JOIN purchase_payments pp ON pp.id = ppp.purchase_payment_id
+		WHERE pp.purchase_id = ANY($1)
+		ORDER BY ppp.id
+	`, purchaseIds)
+	return proofs, err
+}
+
+func (r *purchaseRepository) UpdatePurchasePaymentStatus(ctx context.Context, paymentId int, status string, note string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE purchase_payments SET status = $1, note = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3", status, note, paymentId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *purchaseRepository) RefundPurchasePayment(ctx context.Context, paymentId int, refundedBy int, reason string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `
+		UPDATE purchase_payments
+		SET status = $1, note = $2, refunded_by = $3, refunded_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, entity.PaymentStatusRefunded, reason, refundedBy, paymentId)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+func (r *purchaseRepository) MarkPaymentShipped(ctx context.Context, paymentId int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE purchase_payments SET shipped_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $1", paymentId)
+	if err != nil {
+		return database.MapError(err)
+	}
+	return nil
+}
+
+func (r *purchaseRepository) CreatePaymentProofs(ctx context.Context, paymentId int, fileIds []int) error {
+	for _, fileId := range fileIds {
+		_, err := database.Conn(ctx, r.db).ExecContext(ctx, "INSERT INTO purchase_payment_proofs (purchase_payment_id, file_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", paymentId, fileId)
+		if err != nil {
+			return database.MapError(err)
+		}
+	}
+	return nil
+}
+
+// GetSellerPurchases returns the purchases that include products of the seller,
+// newest first
+func (r *purchaseRepository) GetSellerPurchases(ctx context.Context, sellerId int, query *dto.GetSellerOrdersQuery) ([]entity.Purchase, int, error) {
+	args := []any{sellerId}
+	conditions := []string{"EXISTS (SELECT 1 FROM purchase_items pi WHERE pi.seller_id = $1 AND pi.purchase_id = purchase.id)"}
+
+	if query.Status != "" {
+		args = append(args, query.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if query.PaymentStatus != "" {
+		args = append(args, query.PaymentStatus)
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM purchase_payments pp WHERE pp.seller_id = $1 AND pp.purchase_id = purchase.id AND pp.status = $%d)", len(args)))
+	}
+
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &total, "SELECT COUNT(*) FROM purchase "+where, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, query.Limit, query.Offset)
+	purchases := []entity.Purchase{}
+	err = sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &purchases, fmt.Sprintf(
+		"SELECT * FROM purchase %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d",
+		where, len(args)-1, len(args),
+	), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return purchases, total, nil
+}
+
+func (r *purchaseRepository) GetSellerPurchaseItems(ctx context.Context, sellerId int, purchaseIds []int) ([]entity.PurchaseItem, error) {
+	items := []entity.PurchaseItem{}
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &items, `
+		SELECT purchase_id, product_id, seller_id, name, category, qty, price, sku,
+			COALESCE(file_id::TEXT, '') AS file_id, file_url, file_thumbnail_url
+		FROM purchase_items
+		WHERE seller_id = $1 AND purchase_id = ANY($2)
+		ORDER BY id
+	`, sellerId, purchaseIds)
+	return items, err
+}
+
+func (r *purchaseRepository) GetSellerPayments(ctx context.Context, sellerId int, purchaseIds []int) ([]entity.PurchasePayment, error) {
+	payments := []entity.PurchasePayment{}
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &payments, "SELECT * FROM purchase_payments WHERE seller_id = $1 AND purchase_id = ANY($2)", sellerId, purchaseIds)
+	return payments, err
+}
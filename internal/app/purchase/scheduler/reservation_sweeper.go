@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+// StartReservationSweeper periodically releases the stock held by unpaid
+// purchases whose reservation has expired. It is safe to run on every replica.
+func StartReservationSweeper(ctx context.Context, service contracts.PurchaseService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				released, err := service.ReleaseExpiredReservations(ctx)
+				if err != nil {
+					log.Error(log.LogInfo{
+						"error": err.Error(),
+					}, "[SCHEDULER][ReservationSweeper] failed to release expired reservations")
+					continue
+				}
+
+				if released > 0 {
+					log.Info(log.LogInfo{
+						"released": released,
+					}, "[SCHEDULER][ReservationSweeper] released expired reservations")
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
+
+// GetPurchases lists the purchase history of the buyer
+func (s *purchaseService) GetPurchases(ctx context.Context, buyerId int, query *dto.GetPurchasesQuery) (*dto.PaginatedResponse[dto.PurchaseResponse], error) {
+	valErr := s.validator.Validate(query)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	query.Normalize()
+
+	purchases, total, err := s.repo.GetBuyerPurchases(ctx, buyerId, query)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	data, err := s.toPurchaseResponses(ctx, purchases)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.PaginatedResponse[dto.PurchaseResponse]{
+		Data: data,
+		Meta: dto.PaginationMeta{
+			Limit:  query.Limit,
+			Offset: query.Offset,
+			Total:  total,
+		},
+	}, nil
+}
+
+// GetPurchase returns a purchase to its buyer, or to anyone holding the lookup
+// token returned at checkout. userId is 0 for guests.
+func (s *purchaseService) GetPurchase(ctx context.Context, userId int, purchaseId string, lookupToken string) (dto.PurchaseResponse, error) {
+	id, err := parsePurchaseId(purchaseId)
+	if err != nil {
+		return dto.PurchaseResponse{}, err
+	}
+
+	purchase, err := s.repo.GetPurchaseById(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dto.PurchaseResponse{}, fiber.NewError(fiber.StatusNotFound, "purchase not found")
+		}
+
+		return dto.PurchaseResponse{}, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if !s.canAccess(purchase, userId, lookupToken) {
+		// Don't reveal that the purchase exists
+		return dto.PurchaseResponse{}, fiber.NewError(fiber.StatusNotFound, "purchase not found")
+	}
+
+	res, err := s.toPurchaseResponses(ctx, []entity.Purchase{purchase})
+	if err != nil {
+		return dto.PurchaseResponse{}, err
+	}
+
+	return res[0], nil
+}
+
+// CompletePurchase lets the buyer, or a guest holding the lookup token, confirm
+// that a shipped purchase has arrived
+func (s *purchaseService) CompletePurchase(ctx context.Context, userId int, purchaseId string, lookupToken string) error {
+	id, err := parsePurchaseId(purchaseId)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		purchase, err := s.lockPurchase(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !s.canAccess(purchase, userId, lookupToken) {
+			return fiber.NewError(fiber.StatusNotFound, "purchase not found")
+		}
+
+		return s.transition(ctx, &purchase, entity.PurchaseStatusCompleted, userId, "")
+	})
+}
+
+// canAccess reports whether the user is the buyer of the purchase or holds its
+// lookup token
+func (s *purchaseService) canAccess(purchase entity.Purchase, userId int, lookupToken string) bool {
+	if userId != 0 && purchase.BuyerID.Valid && int(purchase.BuyerID.Int32) == userId {
+		return true
+	}
+
+	return lookupToken != "" && s.signature.VerifyWithExpiry(strconv.Itoa(purchase.ID), lookupToken)
+}
+
+// toPurchaseResponses builds the responses of the purchases along with their
+// per-seller payment details
+func (s *purchaseService) toPurchaseResponses(ctx context.Context, purchases []entity.Purchase) ([]dto.PurchaseResponse, error) {
+	purchaseIds := make([]int, 0, len(purchases))
+	for _, purchase := range purchases {
+		purchaseIds = append(purchaseIds, purchase.ID)
+	}
+
+	payments, err := s.repo.GetPaymentsByPurchaseIds(ctx, purchaseIds)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	proofs, err := s.repo.GetPaymentProofsByPurchaseIds(ctx, purchaseIds)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	proofsByPayment := make(map[int][]string)
+	for _, proof := range proofs {
+		proofsByPayment[proof.PurchasePaymentID] = append(proofsByPayment[proof.PurchasePaymentID], strconv.Itoa(proof.FileID))
+	}
+
+	paymentsByPurchase := make(map[int][]dto.PaymentDetail)
+	for _, payment := range payments {
+		paymentDetail := toPaymentDetail(payment)
+		paymentDetail.PaymentProofIDs = proofsByPayment[payment.ID]
+		paymentsByPurchase[payment.PurchaseID] = append(paymentsByPurchase[payment.PurchaseID], paymentDetail)
+	}
+
+	res := make([]dto.PurchaseResponse, 0, len(purchases))
+	for _, purchase := range purchases {
+		paymentDetails := paymentsByPurchase[purchase.ID]
+		if paymentDetails == nil {
+			paymentDetails = []dto.PaymentDetail{}
+		}
+
+		var totalPrice, refundedPrice money.Money
+		for _, paymentDetail := range paymentDetails {
+			if paymentDetail.Status == entity.PaymentStatusRefunded {
+				refundedPrice = refundedPrice.Add(paymentDetail.TotalPrice)
+			} else {
+				totalPrice = totalPrice.Add(paymentDetail.TotalPrice)
+			}
+		}
+
+		expiresAt := ""
+		if purchase.ExpiresAt.Valid {
+			expiresAt = purchase.ExpiresAt.Time.Format(time.RFC3339)
+		}
+
+		res = append(res, dto.PurchaseResponse{
+			PurchaseID:     strconv.Itoa(purchase.ID),
+			Status:         purchase.Status,
+			PurchasedItems: purchase.PurchasedItems,
+			TotalPrice:     totalPrice,
+			RefundedPrice:  refundedPrice,
+			Currency:       money.DefaultCurrency,
+			Discounts:      discountLines(paymentDetails),
+			PaymentDetails: paymentDetails,
+			ExpiresAt:      expiresAt,
+			CreatedAt:      purchase.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return res, nil
+}
+
+// discountLines lists the voucher discounts given by the sellers of a purchase.
+// A refunded share gave its voucher back, so its discount no longer counts.
+func discountLines(paymentDetails []dto.PaymentDetail) []dto.DiscountLine {
+	discounts := []dto.DiscountLine{}
+	for _, paymentDetail := range paymentDetails {
+		if paymentDetail.VoucherCode == "" || paymentDetail.Status == entity.PaymentStatusRefunded {
+			continue
+		}
+
+		discounts = append(discounts, dto.DiscountLine{
+			VoucherCode: paymentDetail.VoucherCode,
+			SellerID:    paymentDetail.SellerID,
+			Amount:      paymentDetail.Discount,
+		})
+	}
+
+	return discounts
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// CancelPurchase lets the buyer, or a guest holding the lookup token, cancel a
+// pending purchase while no seller holds a payment proof they haven't rejected.
+// The stock still reserved is given back; shares a seller already refunded
+// stay refunded. Cancelled is final.
+func (s *purchaseService) CancelPurchase(ctx context.Context, userId int, purchaseId string, lookupToken string, req dto.CancelPurchaseRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	id, err := parsePurchaseId(purchaseId)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		purchase, err := s.lockPurchase(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !s.canAccess(purchase, userId, lookupToken) {
+			return fiber.NewError(fiber.StatusNotFound, "purchase not found")
+		}
+
+		if purchase.Status != entity.PurchaseStatusPending {
+			return fiber.NewError(fiber.StatusConflict, "cannot cancel a "+purchase.Status+" purchase")
+		}
+
+		payments, err := s.repo.GetPurchasePayments(ctx, purchase.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if hasOutstandingPayment(payments) {
+			return fiber.NewError(fiber.StatusConflict, "payment was already uploaded, ask the seller for a refund")
+		}
+
+		for _, purchasedItem := range activeItems(purchase, payments) {
+			err := s.repo.ReleaseReservedQuantity(ctx, purchasedItem.ProductID, purchasedItem.Quantity)
+			if err != nil {
+				if domain.IsRequestError(err) {
+					return err
+				}
+
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+		}
+
+		err = s.voucherService.ReleaseRedemptions(ctx, purchase.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.transition(ctx, &purchase, entity.PurchaseStatusCancelled, userId, req.Reason)
+	})
+}
+
+// RefundPayment gives the buyer the share of a paid purchase back that belongs
+// to the seller, puts the items of the seller back on sale and gives the use of
+// the voucher of the seller back. The purchase is cancelled once every seller
+// has refunded.
+func (s *purchaseService) RefundPayment(ctx context.Context, sellerId int, purchaseId string, req dto.RefundPaymentRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	id, err := parsePurchaseId(purchaseId)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		purchase, err := s.lockPurchase(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		payments, payment, err := s.sellerPayment(ctx, purchase.ID, sellerId)
+		if err != nil {
+			return err
+		}
+
+		if purchase.Status != entity.PurchaseStatusPaid && purchase.Status != entity.PurchaseStatusConfirmed {
+			return fiber.NewError(fiber.StatusConflict, "cannot refund a "+purchase.Status+" purchase")
+		}
+
+		if payment.Status == entity.PaymentStatusRefunded {
+			return fiber.NewError(fiber.StatusConflict, "payment was already refunded")
+		}
+
+		if payment.ShippedAt.Valid {
+			return fiber.NewError(fiber.StatusConflict, "cannot refund a shipped share")
+		}
+
+		err = s.repo.RefundPurchasePayment(ctx, payment.ID, sellerId, req.Reason)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		err = s.voucherService.ReleaseSellerRedemptions(ctx, purchase.ID, sellerId)
+		if err != nil {
+			return err
+		}
+
+		// The stock was committed when the purchase was paid
+		for _, purchasedItem := range purchase.PurchasedItems {
+			if purchasedItem.SellerID != sellerId {
+				continue
+			}
+
+			err := s.repo.RestockQuantity(ctx, purchasedItem.ProductID, purchasedItem.Quantity)
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+		}
+
+		payments = withPaymentStatus(payments, payment.ID, entity.PaymentStatusRefunded)
+		err = s.settlePaidPurchase(ctx, &purchase, payments, sellerId)
+		if err != nil {
+			return err
+		}
+
+		// The other sellers may all have shipped their shares already
+		return s.settleShipping(ctx, &purchase, payments, sellerId, "")
+	})
+}
+
+// settlePaidPurchase moves a paid purchase on once every seller has handled
+// their share: it is cancelled when all of them refunded and confirmed when
+// the rest confirmed the payment.
+func (s *purchaseService) settlePaidPurchase(ctx context.Context, purchase *entity.Purchase, payments []entity.PurchasePayment, actorUserId int) error {
+	if purchase.Status != entity.PurchaseStatusPaid && purchase.Status != entity.PurchaseStatusConfirmed {
+		return nil
+	}
+
+	refunded := 0
+	for _, payment := range payments {
+		switch payment.Status {
+		case entity.PaymentStatusRefunded:
+			refunded++
+		case entity.PaymentStatusConfirmed:
+		default:
+			return nil
+		}
+	}
+
+	if refunded == len(payments) {
+		err := s.voucherService.ReleaseRedemptions(ctx, purchase.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.transition(ctx, purchase, entity.PurchaseStatusCancelled, actorUserId, "refunded by every seller")
+	}
+
+	if purchase.Status != entity.PurchaseStatusPaid {
+		return nil
+	}
+
+	return s.transition(ctx, purchase, entity.PurchaseStatusConfirmed, actorUserId, "payment confirmed by every seller")
+}
+
+// hasOutstandingPayment reports whether a seller received a payment proof they
+// haven't rejected or refunded
+func hasOutstandingPayment(payments []entity.PurchasePayment) bool {
+	for _, payment := range payments {
+		if payment.Status == entity.PaymentStatusSubmitted || payment.Status == entity.PaymentStatusConfirmed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// activeItems returns the items of the purchase whose seller hasn't refunded
+// their share
+func activeItems(purchase entity.Purchase, payments []entity.PurchasePayment) []entity.PurchaseItem {
+	refunded := make(map[int]bool)
+	for _, payment := range payments {
+		if payment.Status == entity.PaymentStatusRefunded {
+			refunded[payment.SellerID] = true
+		}
+	}
+
+	items := make([]entity.PurchaseItem, 0, len(purchase.PurchasedItems))
+	for _, item := range purchase.PurchasedItems {
+		if !refunded[item.SellerID] {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// withPaymentStatus returns a copy of the payments with the status of one of
+// them replaced, to reason about a change made in the same transaction
+func withPaymentStatus(payments []entity.PurchasePayment, paymentId int, status string) []entity.PurchasePayment {
+	updated := make([]entity.PurchasePayment, len(payments))
+	for i, payment := range payments {
+		if payment.ID == paymentId {
+			payment.Status = status
+		}
+		updated[i] = payment
+	}
+
+	return updated
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+func TestRefundPayment(t *testing.T) {
+	shipped := sql.NullTime{Time: time.Now(), Valid: true}
+
+	tests := []struct {
+		name              string
+		status            string
+		sellerStatus      string
+		otherSellerStatus string
+		otherShipped      bool
+		sellerShipped     bool
+		wantCode          int
+		wantStatus        string
+		wantReleased      []int
+	}{
+		{
+			name:              "one of two sellers refunds",
+			status:            entity.PurchaseStatusConfirmed,
+			sellerStatus:      entity.PaymentStatusConfirmed,
+			otherSellerStatus: entity.PaymentStatusConfirmed,
+			wantStatus:        entity.PurchaseStatusConfirmed,
+		},
+		{
+			name:              "last seller refunds",
+			status:            entity.PurchaseStatusConfirmed,
+			sellerStatus:      entity.PaymentStatusConfirmed,
+			otherSellerStatus: entity.PaymentStatusRefunded,
+			wantStatus:        entity.PurchaseStatusCancelled,
+			wantReleased:      []int{1},
+		},
+		{
+			name:              "refund while the other seller hasn't confirmed",
+			status:            entity.PurchaseStatusPaid,
+			sellerStatus:      entity.PaymentStatusSubmitted,
+			otherSellerStatus: entity.PaymentStatusSubmitted,
+			wantStatus:        entity.PurchaseStatusPaid,
+		},
+		{
+			name:              "refund confirms a purchase the other seller confirmed",
+			status:            entity.PurchaseStatusPaid,
+			sellerStatus:      entity.PaymentStatusSubmitted,
+			otherSellerStatus: entity.PaymentStatusConfirmed,
+			wantStatus:        entity.PurchaseStatusConfirmed,
+		},
+		{
+			name:              "refund ships a purchase the other seller shipped",
+			status:            entity.PurchaseStatusConfirmed,
+			sellerStatus:      entity.PaymentStatusConfirmed,
+			otherSellerStatus: entity.PaymentStatusConfirmed,
+			otherShipped:      true,
+			wantStatus:        entity.PurchaseStatusShipped,
+		},
+		{
+			name:              "already refunded",
+			status:            entity.PurchaseStatusConfirmed,
+			sellerStatus:      entity.PaymentStatusRefunded,
+			otherSellerStatus: entity.PaymentStatusConfirmed,
+			wantCode:          fiber.StatusConflict,
+		},
+		{
+			name:              "shipped share",
+			status:            entity.PurchaseStatusConfirmed,
+			sellerStatus:      entity.PaymentStatusConfirmed,
+			otherSellerStatus: entity.PaymentStatusConfirmed,
+			sellerShipped:     true,
+			wantCode:          fiber.StatusConflict,
+		},
+		{
+			name:              "pending purchase",
+			status:            entity.PurchaseStatusPending,
+			sellerStatus:      entity.PaymentStatusPending,
+			otherSellerStatus: entity.PaymentStatusPending,
+			wantCode:          fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPurchaseFixture()
+			purchase, payments := twoSellerPurchase(tt.status, tt.sellerStatus, tt.otherSellerStatus)
+			if tt.sellerShipped {
+				payments[0].ShippedAt = shipped
+			}
+			if tt.otherShipped {
+				payments[1].ShippedAt = shipped
+			}
+			f.repo.addPurchase(purchase, payments...)
+
+			err := f.service.RefundPayment(context.Background(), 10, "1", dto.RefundPaymentRequest{Reason: "out of stock"})
+
+			if tt.wantCode != 0 {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) || fiberErr.Code != tt.wantCode {
+					t.Fatalf("RefundPayment() error = %v, want status %d", err, tt.wantCode)
+				}
+
+				if f.uow.committed != 0 {
+					t.Errorf("transaction committed, want it rolled back")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("RefundPayment() error = %v", err)
+			}
+
+			if got := f.repo.payments[11].Status; got != entity.PaymentStatusRefunded {
+				t.Errorf("payment status = %s, want %s", got, entity.PaymentStatusRefunded)
+			}
+
+			if got := f.repo.purchases[1].Status; got != tt.wantStatus {
+				t.Errorf("purchase status = %s, want %s", got, tt.wantStatus)
+			}
+
+			if f.repo.restocked[100] != 2 || f.repo.restocked[200] != 0 {
+				t.Errorf("restocked = %v, want only the 2 items of the seller", f.repo.restocked)
+			}
+
+			if !slices.Equal(f.vouchers.sellerReleased, []string{"1/10"}) {
+				t.Errorf("seller redemptions released = %v, want [1/10]", f.vouchers.sellerReleased)
+			}
+
+			if !slices.Equal(f.vouchers.released, tt.wantReleased) {
+				t.Errorf("purchase redemptions released = %v, want %v", f.vouchers.released, tt.wantReleased)
+			}
+		})
+	}
+}
+
+func TestRefundPaymentBySellerNotInPurchase(t *testing.T) {
+	f := newPurchaseFixture()
+	purchase, payments := twoSellerPurchase(entity.PurchaseStatusConfirmed, entity.PaymentStatusConfirmed, entity.PaymentStatusConfirmed)
+	f.repo.addPurchase(purchase, payments...)
+
+	err := f.service.RefundPayment(context.Background(), 30, "1", dto.RefundPaymentRequest{Reason: "out of stock"})
+
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusForbidden {
+		t.Fatalf("RefundPayment() error = %v, want status %d", err, fiber.StatusForbidden)
+	}
+
+	if len(f.vouchers.sellerReleased) != 0 || len(f.repo.restocked) != 0 {
+		t.Errorf("refund of another seller released vouchers %v and restocked %v", f.vouchers.sellerReleased, f.repo.restocked)
+	}
+}
+
+func TestCancelPurchase(t *testing.T) {
+	tests := []struct {
+		name              string
+		sellerStatus      string
+		otherSellerStatus string
+		wantCode          int
+		wantReleased      map[int]int
+	}{
+		{
+			name:              "nothing paid",
+			sellerStatus:      entity.PaymentStatusPending,
+			otherSellerStatus: entity.PaymentStatusPending,
+			wantReleased:      map[int]int{100: 2, 200: 3},
+		},
+		{
+			name:              "rejected proof",
+			sellerStatus:      entity.PaymentStatusRejected,
+			otherSellerStatus: entity.PaymentStatusPending,
+			wantReleased:      map[int]int{100: 2, 200: 3},
+		},
+		{
+			name:              "refunded share keeps its stock released once",
+			sellerStatus:      entity.PaymentStatusRefunded,
+			otherSellerStatus: entity.PaymentStatusPending,
+			wantReleased:      map[int]int{200: 3},
+		},
+		{
+			name:              "proof waiting for the seller",
+			sellerStatus:      entity.PaymentStatusSubmitted,
+			otherSellerStatus: entity.PaymentStatusPending,
+			wantCode:          fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPurchaseFixture()
+			purchase, payments := twoSellerPurchase(entity.PurchaseStatusPending, tt.sellerStatus, tt.otherSellerStatus)
+			f.repo.addPurchase(purchase, payments...)
+
+			err := f.service.CancelPurchase(context.Background(), 5, "1", "", dto.CancelPurchaseRequest{Reason: "changed my mind"})
+
+			if tt.wantCode != 0 {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) || fiberErr.Code != tt.wantCode {
+					t.Fatalf("CancelPurchase() error = %v, want status %d", err, tt.wantCode)
+				}
+
+				if f.repo.purchases[1].Status != entity.PurchaseStatusPending {
+					t.Errorf("purchase status = %s, want it still pending", f.repo.purchases[1].Status)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("CancelPurchase() error = %v", err)
+			}
+
+			if got := f.repo.purchases[1].Status; got != entity.PurchaseStatusCancelled {
+				t.Errorf("purchase status = %s, want %s", got, entity.PurchaseStatusCancelled)
+			}
+
+			if len(f.repo.released) != len(tt.wantReleased) {
+				t.Fatalf("released = %v, want %v", f.repo.released, tt.wantReleased)
+			}
+
+			for productId, quantity := range tt.wantReleased {
+				if f.repo.released[productId] != quantity {
+					t.Errorf("released = %v, want %v", f.repo.released, tt.wantReleased)
+				}
+			}
+
+			if !slices.Equal(f.vouchers.released, []int{1}) {
+				t.Errorf("purchase redemptions released = %v, want [1]", f.vouchers.released)
+			}
+		})
+	}
+}
+
+func TestDiscountLinesSkipRefundedShares(t *testing.T) {
+	paymentDetails := []dto.PaymentDetail{
+		{SellerID: "10", Status: entity.PaymentStatusRefunded, VoucherCode: "TEN", Discount: idr(10000)},
+		{SellerID: "20", Status: entity.PaymentStatusConfirmed, VoucherCode: "TWENTY", Discount: idr(20000)},
+		{SellerID: "30", Status: entity.PaymentStatusConfirmed},
+	}
+
+	got := discountLines(paymentDetails)
+	if len(got) != 1 || got[0].VoucherCode != "TWENTY" || got[0].SellerID != "20" {
+		t.Errorf("discountLines() = %+v, want only the voucher of seller 20", got)
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// GetSellerOrders lists the purchases that include products of the seller,
+// limited to the items and the payment share of that seller
+func (s *purchaseService) GetSellerOrders(ctx context.Context, sellerId int, query *dto.GetSellerOrdersQuery) (*dto.PaginatedResponse[dto.SellerOrderResponse], error) {
+	valErr := s.validator.Validate(query)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	query.Normalize()
+
+	purchases, total, err := s.repo.GetSellerPurchases(ctx, sellerId, query)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	purchaseIds := make([]int, 0, len(purchases))
+	for _, purchase := range purchases {
+		purchaseIds = append(purchaseIds, purchase.ID)
+	}
+
+	items, err := s.repo.GetSellerPurchaseItems(ctx, sellerId, purchaseIds)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	payments, err := s.repo.GetSellerPayments(ctx, sellerId, purchaseIds)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	itemsByPurchase := make(map[int][]entity.PurchaseItem)
+	for _, item := range items {
+		itemsByPurchase[item.PurchaseID] = append(itemsByPurchase[item.PurchaseID], item)
+	}
+
+	paymentByPurchase := make(map[int]entity.PurchasePayment)
+	for _, payment := range payments {
+		paymentByPurchase[payment.PurchaseID] = payment
+	}
+
+	data := make([]dto.SellerOrderResponse, 0, len(purchases))
+	for _, purchase := range purchases {
+		purchasedItems := itemsByPurchase[purchase.ID]
+		if purchasedItems == nil {
+			purchasedItems = []entity.PurchaseItem{}
+		}
+
+		data = append(data, dto.SellerOrderResponse{
+			PurchaseID:          strconv.Itoa(purchase.ID),
+			Status:              purchase.Status,
+			SenderName:          purchase.SenderName,
+			SenderContactType:   purchase.SenderContactType,
+			SenderContactDetail: purchase.SenderContactDetail,
+			PurchasedItems:      purchasedItems,
+			Payment:             toPaymentDetail(paymentByPurchase[purchase.ID]),
+			CreatedAt:           purchase.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &dto.PaginatedResponse[dto.SellerOrderResponse]{
+		Data: data,
+		Meta: dto.PaginationMeta{
+			Limit:  query.Limit,
+			Offset: query.Offset,
+			Total:  total,
+		},
+	}, nil
+}
+
+// ConfirmPayment marks the payment share of the seller as received. The
+// purchase is confirmed once every seller has confirmed their share.
+func (s *purchaseService) ConfirmPayment(ctx context.Context, sellerId int, purchaseId string) error {
+	id, err := parsePurchaseId(purchaseId)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		purchase, err := s.lockPurchase(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		payments, payment, err := s.sellerPayment(ctx, purchase.ID, sellerId)
+		if err != nil {
+			return err
+		}
+
+		if payment.Status != entity.PaymentStatusSubmitted {
+			return fiber.NewError(fiber.StatusConflict, "no payment proof waiting for confirmation")
+		}
+
+		err = s.repo.UpdatePurchasePaymentStatus(ctx, payment.ID, entity.PaymentStatusConfirmed, "")
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return s.settlePaidPurchase(ctx, &purchase, withPaymentStatus(payments, payment.ID, entity.PaymentStatusConfirmed), sellerId)
+	})
+}
+
+// RejectPayment turns down the payment proof the seller received. A paid
+// purchase goes back to pending and its stock is reserved again. Either way the
+// buyer gets a fresh reservation TTL to upload a new proof.
+func (s *purchaseService) RejectPayment(ctx context.Context, sellerId int, purchaseId string, req dto.RejectPaymentRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	id, err := parsePurchaseId(purchaseId)
+	if err != nil {
+		return err
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		purchase, err := s.lockPurchase(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		payments, payment, err := s.sellerPayment(ctx, purchase.ID, sellerId)
+		if err != nil {
+			return err
+		}
+
+		if payment.Status != entity.PaymentStatusSubmitted {
+			return fiber.NewError(fiber.StatusConflict, "no payment proof waiting for confirmation")
+		}
+
+		if purchase.Status != entity.PurchaseStatusPending && purchase.Status != entity.PurchaseStatusPaid {
+			return fiber.NewError(fiber.StatusConflict, "cannot reject payment for a "+purchase.Status+" purchase")
+		}
+
+		err = s.repo.UpdatePurchasePaymentStatus(ctx, payment.ID, entity.PaymentStatusRejected, req.Reason)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		err = s.repo.ExtendReservation(ctx, purchase.ID, s.reservationTTL)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if purchase.Status != entity.PurchaseStatusPaid {
+			return nil
+		}
+
+		err = s.transition(ctx, &purchase, entity.PurchaseStatusPending, sellerId, req.Reason)
+		if err != nil {
+			return err
+		}
+
+		for _, purchasedItem := range activeItems(purchase, payments) {
+			err := s.repo.RestoreReservedQuantity(ctx, purchasedItem.ProductID, purchasedItem.Quantity)
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+		}
+
+		return nil
+	})
+}
+
+// sellerPayment returns every payment share of the purchase along with the one
+// of the seller
+func (s *purchaseService) sellerPayment(ctx context.Context, purchaseId int, sellerId int) ([]entity.PurchasePayment, entity.PurchasePayment, error) {
+	payments, err := s.repo.GetPurchasePayments(ctx, purchaseId)
+	if err != nil {
+		return nil, entity.PurchasePayment{}, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	for _, payment := range payments {
+		if payment.SellerID == sellerId {
+			return payments, payment, nil
+		}
+	}
+
+	return nil, entity.PurchasePayment{}, fiber.NewError(fiber.StatusForbidden, "purchase does not include products of user")
+}
+
+func toPaymentDetail(payment entity.PurchasePayment) dto.PaymentDetail {
+	shippedAt := ""
+	if payment.ShippedAt.Valid {
+		shippedAt = payment.ShippedAt.Time.Format(time.RFC3339)
+	}
+
+	return dto.PaymentDetail{
+		SellerID:          strconv.Itoa(payment.SellerID),
+		Status:            payment.Status,
+		BankAccountName:   payment.BankAccountName,
+		BankAccountHolder: payment.BankAccountHolder,
+		BankAccountNumber: payment.BankAccountNumber,
+		Subtotal:          payment.Subtotal,
+		Discount:          payment.Discount,
+		VoucherCode:       payment.VoucherCode,
+		TotalPrice:        payment.TotalPrice,
+		Note:              payment.Note,
+		ShippedAt:         shippedAt,
+	}
+}
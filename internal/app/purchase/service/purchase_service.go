@@ -2,124 +2,643 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 	"net/mail"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/signature"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
 )
 
+// expiredPurchasesBatchSize is how many expired purchases are released per transaction
+const expiredPurchasesBatchSize = 100
+
 type purchaseService struct {
-	repo      contracts.PurchaseRepository
-	validator validator.ValidatorInterface
+	repo           contracts.PurchaseRepository
+	uow            contracts.UnitOfWork
+	fileService    contracts.FileService
+	voucherService contracts.VoucherService
+	validator      validator.ValidatorInterface
+	signature      signature.SignatureInterface
+	reservationTTL time.Duration
+	lookupTokenTTL time.Duration
 }
 
 func NewPurchaseService(
 	repo contracts.PurchaseRepository,
+	uow contracts.UnitOfWork,
+	fileService contracts.FileService,
+	voucherService contracts.VoucherService,
 	validator validator.ValidatorInterface,
+	signature signature.SignatureInterface,
+	reservationTTL time.Duration,
+	lookupTokenTTL time.Duration,
 ) contracts.PurchaseService {
 	return &purchaseService{
-		repo:      repo,
-		validator: validator,
+		repo:           repo,
+		uow:            uow,
+		fileService:    fileService,
+		voucherService: voucherService,
+		validator:      validator,
+		signature:      signature,
+		reservationTTL: reservationTTL,
+		lookupTokenTTL: lookupTokenTTL,
 	}
 }
 
-func (s *purchaseService) Purchase(ctx context.Context, req dto.PurchaseRequest) (dto.PurchaseResponse, error) {
-	var err error
-	err = s.validateSenderContactDetail(req.SenderContactType, req.SenderContactDetail)
-	if err != nil {
-		return dto.PurchaseResponse{}, err
+// Purchase reserves the requested items and creates a pending purchase.
+// buyerId is 0 for guests, who read the purchase back through its lookup token.
+func (s *purchaseService) Purchase(ctx context.Context, buyerId int, req dto.PurchaseRequest) (dto.PurchaseResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return dto.PurchaseResponse{}, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
 	}
 
-	// Prepare purchase
-	var purchasedItems []entity.PurchaseItem
-	var totalPrice float64
-	paymentDetails := make(map[int]dto.PaymentDetail)
+	err := s.validateSenderContactDetail(req.SenderContactType, req.SenderContactDetail)
+	if err != nil {
+		return dto.PurchaseResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
 
+	// Merge repeated products and sort them, so rows are always locked in the
+	// same order and concurrent purchases can't deadlock each other
+	quantities := make(map[int]int)
 	for _, item := range req.PurchasedItems {
-
 		productId, err := strconv.Atoi(item.ProductID)
 		if err != nil {
-			return dto.PurchaseResponse{}, err
+			return dto.PurchaseResponse{}, fiber.NewError(fiber.StatusBadRequest, "invalid product id: "+item.ProductID)
 		}
 
-		product, err := s.repo.GetProductById(ctx, productId)
-		if err != nil {
-			return dto.PurchaseResponse{}, err
+		quantities[productId] += item.Qty
+	}
+
+	productIds := make([]int, 0, len(quantities))
+	for productId := range quantities {
+		productIds = append(productIds, productId)
+	}
+	sort.Ints(productIds)
+
+	var res dto.PurchaseResponse
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		var purchasedItems []entity.PurchaseItem
+		var totalPrice money.Money
+		var sellerIds []int
+		paymentDetails := make(map[int]dto.PaymentDetail)
+
+		for _, productId := range productIds {
+			qty := quantities[productId]
+
+			product, err := s.repo.GetProductById(ctx, productId)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fiber.NewError(fiber.StatusNotFound, fmt.Sprintf("product %d not found", productId))
+				}
+
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+
+			// Reserve the stock now; the update only succeeds while enough is left
+			err = s.repo.ReserveQuantity(ctx, productId, qty)
+			if err != nil {
+				if errors.Is(err, domain.ErrInsufficientStock) {
+					return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("insufficient stock for product %d", productId))
+				}
+
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+
+			// Add to purchased items
+			purchasedItems = append(purchasedItems, entity.PurchaseItem{
+				ProductID:        product.ID,
+				SellerID:         product.UserID,
+				Name:             product.Name,
+				Category:         product.Category,
+				Quantity:         qty,
+				Price:            product.Price,
+				SKU:              product.SKU,
+				FileID:           product.FileID,
+				FileURL:          product.FileURL,
+				FileThumbnailURL: product.FileThumbnailURL,
+			})
+
+			// Calculate total price
+			itemPrice := product.Price.Mul(int64(qty))
+			totalPrice = totalPrice.Add(itemPrice)
+
+			if _, ok := paymentDetails[product.UserID]; !ok {
+				seller, err := s.repo.GetSellerById(ctx, product.UserID)
+				if err != nil {
+					return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+				}
+
+				sellerIds = append(sellerIds, product.UserID)
+				paymentDetails[product.UserID] = dto.PaymentDetail{
+					SellerID:          strconv.Itoa(product.UserID),
+					Status:            entity.PaymentStatusPending,
+					BankAccountName:   seller.BankAccountName.String,
+					BankAccountHolder: seller.BankAccountHolder.String,
+					BankAccountNumber: seller.BankAccountNumber.String,
+				}
+			}
+
+			paymentDetail := paymentDetails[product.UserID]
+			paymentDetail.Subtotal = paymentDetail.Subtotal.Add(itemPrice)
+			paymentDetail.TotalPrice = paymentDetail.TotalPrice.Add(itemPrice)
+			paymentDetails[product.UserID] = paymentDetail
 		}
 
-		// Check quantity
-		if item.Qty > product.Quantity {
-			return dto.PurchaseResponse{}, errors.New("quantity product less than purchased product")
+		purchase := &entity.Purchase{
+			BuyerID:             sql.NullInt32{Int32: int32(buyerId), Valid: buyerId != 0},
+			PurchasedItems:      purchasedItems,
+			SenderName:          req.SenderName,
+			SenderContactType:   req.SenderContactType,
+			SenderContactDetail: req.SenderContactDetail,
+			Status:              entity.PurchaseStatusPending,
 		}
 
-		// Add to purchased items
-		purchasedItems = append(purchasedItems, entity.PurchaseItem{
-			ProductID:        product.ID,
-			Name:             product.Name,
-			Category:         product.Category,
-			Quantity:         item.Qty,
-			Price:            product.Price,
-			SKU:              product.SKU,
-			FileID:           product.FileID,
-			FileURL:          product.FileURL,
-			FileThumbnailURL: product.FileThumbnailURL,
-		})
+		err := s.repo.CreatePurchase(ctx, purchase, s.reservationTTL)
+		if err != nil {
+			if domain.IsRequestError(err) {
+				return err
+			}
 
-		// Calculate total price
-		totalPrice += float64(item.Qty) * product.Price
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
 
-		seller, err := s.repo.GetSellerById(ctx, product.UserID)
+		err = s.repo.CreatePurchaseItems(ctx, purchase.ID, purchasedItems)
 		if err != nil {
-			return dto.PurchaseResponse{}, err
+			if domain.IsRequestError(err) {
+				return err
+			}
+
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 		}
 
-		paymentDetails[product.UserID] = dto.PaymentDetail{
-			BankAccountName:   seller.BankAccountName,
-			BankAccountHolder: seller.BankAccountHolder,
-			BankAccountNumber: seller.BankAccountNumber,
-			TotalPrice:        paymentDetails[product.UserID].TotalPrice + float64(item.Qty)*product.Price,
+		err = s.recordEvent(ctx, purchase.ID, "", purchase.Status, 0, "")
+		if err != nil {
+			return err
 		}
 
-	}
-	purchaseId, err := s.repo.CreatePurchase(ctx, purchasedItems, req.SenderName, req.SenderContactType, req.SenderContactDetail)
+		// The voucher only discounts the share of the seller who issued it
+		if req.VoucherCode != "" {
+			discount, err := s.voucherService.Redeem(ctx, &dto.RedeemVoucherRequest{
+				Code:         req.VoucherCode,
+				PurchaseID:   purchase.ID,
+				BuyerID:      buyerId,
+				BuyerContact: req.SenderContactDetail,
+				Items:        purchasedItems,
+			})
+			if err != nil {
+				return err
+			}
+
+			sellerId, _ := strconv.Atoi(discount.SellerID)
+			paymentDetail := paymentDetails[sellerId]
+			paymentDetail.Discount = discount.Amount
+			paymentDetail.VoucherCode = discount.VoucherCode
+			paymentDetail.TotalPrice = paymentDetail.Subtotal.Sub(discount.Amount)
+			paymentDetails[sellerId] = paymentDetail
+
+			totalPrice = totalPrice.Sub(discount.Amount)
+		}
+
+		// Flatten map values into a slice and keep a payment row per seller,
+		// so each of them can receive their own proof
+		paymenDetailsSlice := make([]dto.PaymentDetail, 0, len(paymentDetails))
+		for _, sellerId := range sellerIds {
+			paymentDetail := paymentDetails[sellerId]
+
+			err := s.repo.CreatePurchasePayment(ctx, &entity.PurchasePayment{
+				PurchaseID:        purchase.ID,
+				SellerID:          sellerId,
+				BankAccountName:   paymentDetail.BankAccountName,
+				BankAccountHolder: paymentDetail.BankAccountHolder,
+				BankAccountNumber: paymentDetail.BankAccountNumber,
+				Subtotal:          paymentDetail.Subtotal,
+				Discount:          paymentDetail.Discount,
+				VoucherCode:       paymentDetail.VoucherCode,
+				TotalPrice:        paymentDetail.TotalPrice,
+				Status:            paymentDetail.Status,
+			})
+			if err != nil {
+				if domain.IsRequestError(err) {
+					return err
+				}
+
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+
+			paymenDetailsSlice = append(paymenDetailsSlice, paymentDetail)
+		}
+
+		res = dto.PurchaseResponse{
+			PurchaseID:     strconv.Itoa(purchase.ID),
+			Status:         purchase.Status,
+			PurchasedItems: purchasedItems,
+			TotalPrice:     totalPrice,
+			Currency:       money.DefaultCurrency,
+			Discounts:      discountLines(paymenDetailsSlice),
+			PaymentDetails: paymenDetailsSlice,
+			ExpiresAt:      purchase.ExpiresAt.Time.Format(time.RFC3339),
+			CreatedAt:      purchase.CreatedAt.Format(time.RFC3339),
+			LookupToken:    s.signature.SignWithExpiry(strconv.Itoa(purchase.ID), time.Now().Add(s.lookupTokenTTL)),
+		}
+
+		return nil
+	})
 	if err != nil {
 		return dto.PurchaseResponse{}, err
 	}
 
-	// Flatten map values into a slice
-	paymenDetailsSlice := make([]dto.PaymentDetail, 0, len(paymentDetails))
-	for _, v := range paymentDetails {
-		paymenDetailsSlice = append(paymenDetailsSlice, v)
-	}
-
-	return dto.PurchaseResponse{PurchaseID: strconv.FormatInt(purchaseId, 10), PurchasedItems: purchasedItems, TotalPrice: totalPrice, PaymentDetails: paymenDetailsSlice}, err
+	return res, nil
 }
 
-func (s *purchaseService) UploadPayment(ctx context.Context, req dto.UploadPaymentRequest, purchaseId string) error {
+// UploadPayment attaches payment proofs to the share of one seller, or to every
+// unpaid share when no seller is given. The purchase becomes paid once every
+// seller has received a proof, and only before its reservation expires. Only
+// the buyer or a holder of the lookup token may pay; userId is 0 for guests.
+func (s *purchaseService) UploadPayment(ctx context.Context, userId int, purchaseId string, lookupToken string, req dto.UploadPaymentRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
 
-	id, err := strconv.Atoi(purchaseId)
+	id, err := parsePurchaseId(purchaseId)
 	if err != nil {
 		return err
 	}
+
 	purchase, err := s.repo.GetPurchaseById(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.NewError(fiber.StatusNotFound, "purchase not found")
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if !s.canAccess(purchase, userId, lookupToken) {
+		// Don't reveal that the purchase exists
+		return fiber.NewError(fiber.StatusNotFound, "purchase not found")
+	}
+
+	fileIds := make([]int, 0, len(req.FileIDs))
+	for _, fileId := range req.FileIDs {
+		file, err := s.proofFile(ctx, purchase, userId, fileId)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(fileIds, file.ID) {
+			fileIds = append(fileIds, file.ID)
+		}
+	}
+
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		purchase, err := s.lockPurchase(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if purchase.Status != entity.PurchaseStatusPending {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("cannot upload payment for a %s purchase", purchase.Status))
+		}
+
+		// The sweeper may not have released the stock yet, but it is no longer held
+		if purchase.ExpiresAt.Valid && !purchase.ExpiresAt.Time.After(time.Now()) {
+			return fiber.NewError(fiber.StatusConflict, "reservation has expired")
+		}
+
+		payments, err := s.repo.GetPurchasePayments(ctx, purchase.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		targets, err := paymentsToSettle(payments, req.SellerID)
+		if err != nil {
+			return err
+		}
+
+		for _, payment := range targets {
+			err := s.repo.CreatePaymentProofs(ctx, payment.ID, fileIds)
+			if err != nil {
+				if domain.IsRequestError(err) {
+					return err
+				}
+
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+
+			err = s.repo.UpdatePurchasePaymentStatus(ctx, payment.ID, entity.PaymentStatusSubmitted, "")
+			if err != nil {
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+		}
+
+		// Wait for the remaining sellers before the purchase counts as paid
+		if len(targets) < countUnpaid(payments) {
+			return nil
+		}
+
+		err = s.transition(ctx, &purchase, entity.PurchaseStatusPaid, 0, "")
+		if err != nil {
+			return err
+		}
+
+		// The stock was reserved at checkout, paying turns the reservation into a sale
+		for _, purchasedItem := range activeItems(purchase, payments) {
+			err := s.repo.CommitReservedQuantity(ctx, purchasedItem.ProductID, purchasedItem.Quantity)
+			if err != nil {
+				if domain.IsRequestError(err) {
+					return err
+				}
+
+				return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+			}
+		}
+
+		return nil
+	})
+}
+
+// proofFile loads a proof the caller may attach: their own upload, or for
+// guests a file uploaded with the lookup token of the purchase
+func (s *purchaseService) proofFile(ctx context.Context, purchase entity.Purchase, userId int, fileId string) (*entity.File, error) {
+	if userId != 0 {
+		return s.fileService.GetOwnedFile(ctx, userId, fileId)
+	}
+
+	return s.fileService.GetPurchaseFile(ctx, purchase.ID, fileId)
+}
+
+// UpdateStatus lets a seller mark their share of a confirmed purchase as
+// shipped. The purchase is shipped once every seller that didn't refund has
+// shipped their share.
+func (s *purchaseService) UpdateStatus(ctx context.Context, userId int, purchaseId string, req dto.UpdatePurchaseStatusRequest) error {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	id, err := parsePurchaseId(purchaseId)
 	if err != nil {
 		return err
 	}
 
-	for _, purchasedItem := range purchase.PurchasedItems {
-		// TODO: bulk decrease
-		err := s.repo.DecreaseQuantity(ctx, purchasedItem.ProductID, purchasedItem.Quantity)
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		purchase, err := s.lockPurchase(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		payments, payment, err := s.sellerPayment(ctx, purchase.ID, userId)
 		if err != nil {
 			return err
 		}
 
+		if purchase.Status != entity.PurchaseStatusConfirmed {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("cannot ship a %s purchase", purchase.Status))
+		}
+
+		if payment.Status != entity.PaymentStatusConfirmed {
+			return fiber.NewError(fiber.StatusConflict, "cannot ship a "+payment.Status+" payment share")
+		}
+
+		if payment.ShippedAt.Valid {
+			return fiber.NewError(fiber.StatusConflict, "share was already shipped")
+		}
+
+		err = s.repo.MarkPaymentShipped(ctx, payment.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		payment.ShippedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		for i := range payments {
+			if payments[i].ID == payment.ID {
+				payments[i] = payment
+			}
+		}
+
+		return s.settleShipping(ctx, &purchase, payments, userId, req.Note)
+	})
+}
+
+// settleShipping moves a confirmed purchase to shipped once every seller that
+// didn't refund their share has shipped it
+func (s *purchaseService) settleShipping(ctx context.Context, purchase *entity.Purchase, payments []entity.PurchasePayment, actorUserId int, note string) error {
+	if purchase.Status != entity.PurchaseStatusConfirmed {
+		return nil
 	}
-	return nil
+
+	shipped := 0
+	for _, payment := range payments {
+		if payment.Status == entity.PaymentStatusRefunded {
+			continue
+		}
+
+		if !payment.ShippedAt.Valid {
+			return nil
+		}
+
+		shipped++
+	}
+
+	if shipped == 0 {
+		return nil
+	}
+
+	return s.transition(ctx, purchase, entity.PurchaseStatusShipped, actorUserId, note)
+}
+
+// GetEvents returns the status history of a purchase to its buyer, to the
+// sellers involved in it, or to anyone holding its lookup token. userId is 0
+// for guests.
+func (s *purchaseService) GetEvents(ctx context.Context, userId int, purchaseId string, lookupToken string) ([]dto.PurchaseEventResponse, error) {
+	id, err := parsePurchaseId(purchaseId)
+	if err != nil {
+		return nil, err
+	}
+
+	purchase, err := s.repo.GetPurchaseById(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "purchase not found")
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if !s.canAccess(purchase, userId, lookupToken) && !isSellerOf(purchase, userId) {
+		// Don't reveal that the purchase exists
+		return nil, fiber.NewError(fiber.StatusNotFound, "purchase not found")
+	}
+
+	events, err := s.repo.GetPurchaseEvents(ctx, id)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.PurchaseEventResponse, 0, len(events))
+	for _, event := range events {
+		actorUserId := ""
+		if event.ActorUserID.Valid {
+			actorUserId = strconv.Itoa(int(event.ActorUserID.Int32))
+		}
+
+		res = append(res, dto.PurchaseEventResponse{
+			FromStatus:  event.FromStatus.String,
+			ToStatus:    event.ToStatus,
+			ActorUserID: actorUserId,
+			Note:        event.Note,
+			CreatedAt:   event.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return res, nil
+}
+
+// ReleaseExpiredReservations gives the stock of unpaid purchases past their
+// reservation TTL back and marks them expired. It returns how many purchases
+// were expired.
+func (s *purchaseService) ReleaseExpiredReservations(ctx context.Context) (int, error) {
+	total := 0
+
+	for {
+		released := 0
+		err := s.uow.Do(ctx, func(ctx context.Context) error {
+			purchases, err := s.repo.LockExpiredPurchases(ctx, expiredPurchasesBatchSize)
+			if err != nil {
+				return err
+			}
+
+			for _, purchase := range purchases {
+				payments, err := s.repo.GetPurchasePayments(ctx, purchase.ID)
+				if err != nil {
+					return err
+				}
+
+				// Refunded shares already gave their stock back
+				for _, purchasedItem := range activeItems(purchase, payments) {
+					err := s.repo.ReleaseReservedQuantity(ctx, purchasedItem.ProductID, purchasedItem.Quantity)
+					if err != nil {
+						return err
+					}
+				}
+
+				err = s.voucherService.ReleaseRedemptions(ctx, purchase.ID)
+				if err != nil {
+					return err
+				}
+
+				err = s.transition(ctx, &purchase, entity.PurchaseStatusExpired, 0, "reservation expired")
+				if err != nil {
+					return err
+				}
+			}
+
+			released = len(purchases)
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+
+		total += released
+		if released < expiredPurchasesBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// paymentsToSettle picks the unpaid seller shares a proof is uploaded for
+func paymentsToSettle(payments []entity.PurchasePayment, sellerId string) ([]entity.PurchasePayment, error) {
+	if sellerId == "" {
+		var unpaid []entity.PurchasePayment
+		for _, payment := range payments {
+			if isUnpaid(payment) {
+				unpaid = append(unpaid, payment)
+			}
+		}
+
+		if len(unpaid) == 0 {
+			return nil, fiber.NewError(fiber.StatusConflict, "purchase has no unpaid sellers")
+		}
+
+		return unpaid, nil
+	}
+
+	for _, payment := range payments {
+		if strconv.Itoa(payment.SellerID) != sellerId {
+			continue
+		}
+
+		if !isUnpaid(payment) {
+			return nil, fiber.NewError(fiber.StatusConflict, "payment for seller "+sellerId+" has already been uploaded")
+		}
+
+		return []entity.PurchasePayment{payment}, nil
+	}
+
+	return nil, fiber.NewError(fiber.StatusNotFound, "seller "+sellerId+" is not part of the purchase")
+}
+
+// isUnpaid reports whether the seller still waits for a proof, either because
+// none was uploaded or because they rejected it
+func isUnpaid(payment entity.PurchasePayment) bool {
+	return payment.Status == entity.PaymentStatusPending || payment.Status == entity.PaymentStatusRejected
+}
+
+func countUnpaid(payments []entity.PurchasePayment) int {
+	count := 0
+	for _, payment := range payments {
+		if isUnpaid(payment) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// isSellerOf reports whether the purchase includes products of the user
+func isSellerOf(purchase entity.Purchase, userId int) bool {
+	return userId != 0 && slices.ContainsFunc(purchase.PurchasedItems, func(item entity.PurchaseItem) bool {
+		return item.SellerID == userId
+	})
+}
+
+func parsePurchaseId(purchaseId string) (int, error) {
+	id, err := strconv.Atoi(purchaseId)
+	if err != nil {
+		return 0, fiber.NewError(fiber.StatusNotFound, "purchase not found")
+	}
+
+	return id, nil
+}
+
+// lockPurchase reads the purchase and locks it for the rest of the transaction
+func (s *purchaseService) lockPurchase(ctx context.Context, id int) (entity.Purchase, error) {
+	purchase, err := s.repo.LockPurchaseById(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Purchase{}, fiber.NewError(fiber.StatusNotFound, "purchase not found")
+		}
+
+		return entity.Purchase{}, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return purchase, nil
 }
 
 func validatePhone(phone string) error {
@@ -0,0 +1,579 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/signature"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+// fakeUnitOfWork runs fn right away and counts how its transactions ended
+type fakeUnitOfWork struct {
+	committed  int
+	rolledBack int
+}
+
+func (u *fakeUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := fn(ctx); err != nil {
+		u.rolledBack++
+		return err
+	}
+
+	u.committed++
+	return nil
+}
+
+// fakePurchaseRepository keeps purchases and their payments in memory and
+// records how the stock of each product moved. The methods the tests don't
+// reach panic through the embedded nil interface.
+type fakePurchaseRepository struct {
+	contracts.PurchaseRepository
+
+	products  map[int]*entity.Product
+	purchases map[int]*entity.Purchase
+	items     map[int][]entity.PurchaseItem
+	payments  map[int]*entity.PurchasePayment
+	proofs    map[int][]int
+	events    []entity.PurchaseEvent
+	extended  []int
+
+	// stock moves by product
+	committed map[int]int
+	released  map[int]int
+	restored  map[int]int
+	restocked map[int]int
+}
+
+func newFakePurchaseRepository() *fakePurchaseRepository {
+	return &fakePurchaseRepository{
+		products:  map[int]*entity.Product{},
+		purchases: map[int]*entity.Purchase{},
+		items:     map[int][]entity.PurchaseItem{},
+		payments:  map[int]*entity.PurchasePayment{},
+		proofs:    map[int][]int{},
+		committed: map[int]int{},
+		released:  map[int]int{},
+		restored:  map[int]int{},
+		restocked: map[int]int{},
+	}
+}
+
+func (r *fakePurchaseRepository) addPurchase(purchase entity.Purchase, payments ...entity.PurchasePayment) {
+	r.purchases[purchase.ID] = &purchase
+	for _, payment := range payments {
+		payment.PurchaseID = purchase.ID
+		r.payments[payment.ID] = &payment
+	}
+}
+
+func (r *fakePurchaseRepository) GetProductById(_ context.Context, productId int) (entity.Product, error) {
+	product, ok := r.products[productId]
+	if !ok {
+		return entity.Product{}, sql.ErrNoRows
+	}
+
+	return *product, nil
+}
+
+func (r *fakePurchaseRepository) GetSellerById(_ context.Context, sellerId int) (entity.User, error) {
+	return entity.User{
+		ID:              sellerId,
+		BankAccountName: sql.NullString{String: "Bank " + strconv.Itoa(sellerId), Valid: true},
+	}, nil
+}
+
+func (r *fakePurchaseRepository) ReserveQuantity(_ context.Context, productId int, quantity int) error {
+	product := r.products[productId]
+	if product.Quantity < quantity {
+		return domain.ErrInsufficientStock
+	}
+
+	product.Quantity -= quantity
+	product.ReservedQuantity += quantity
+	return nil
+}
+
+func (r *fakePurchaseRepository) CreatePurchase(_ context.Context, purchase *entity.Purchase, reservationTTL time.Duration) error {
+	purchase.ID = len(r.purchases) + 1
+	purchase.CreatedAt = time.Now()
+	purchase.ExpiresAt = sql.NullTime{Time: purchase.CreatedAt.Add(reservationTTL), Valid: true}
+
+	copied := *purchase
+	r.purchases[purchase.ID] = &copied
+	return nil
+}
+
+func (r *fakePurchaseRepository) CreatePurchaseItems(_ context.Context, purchaseId int, items []entity.PurchaseItem) error {
+	r.items[purchaseId] = append(r.items[purchaseId], items...)
+	return nil
+}
+
+func (r *fakePurchaseRepository) CreatePurchasePayment(_ context.Context, payment *entity.PurchasePayment) error {
+	payment.ID = len(r.payments) + 1
+
+	copied := *payment
+	r.payments[payment.ID] = &copied
+	return nil
+}
+
+func (r *fakePurchaseRepository) GetPurchaseById(_ context.Context, purchaseId int) (entity.Purchase, error) {
+	purchase, ok := r.purchases[purchaseId]
+	if !ok {
+		return entity.Purchase{}, sql.ErrNoRows
+	}
+
+	return *purchase, nil
+}
+
+func (r *fakePurchaseRepository) LockPurchaseById(ctx context.Context, purchaseId int) (entity.Purchase, error) {
+	return r.GetPurchaseById(ctx, purchaseId)
+}
+
+func (r *fakePurchaseRepository) LockExpiredPurchases(_ context.Context, limit int) ([]entity.Purchase, error) {
+	var expired []entity.Purchase
+	for _, purchase := range r.purchases {
+		if purchase.Status != entity.PurchaseStatusPending || !purchase.ExpiresAt.Valid || purchase.ExpiresAt.Time.After(time.Now()) {
+			continue
+		}
+
+		payments, _ := r.GetPurchasePayments(context.Background(), purchase.ID)
+		if hasOutstandingPayment(payments) {
+			continue
+		}
+
+		expired = append(expired, *purchase)
+	}
+
+	slices.SortFunc(expired, func(a, b entity.Purchase) int { return a.ID - b.ID })
+	return expired[:min(limit, len(expired))], nil
+}
+
+func (r *fakePurchaseRepository) UpdatePurchaseStatus(_ context.Context, purchaseId int, status string) error {
+	r.purchases[purchaseId].Status = status
+	return nil
+}
+
+func (r *fakePurchaseRepository) ExtendReservation(_ context.Context, purchaseId int, reservationTTL time.Duration) error {
+	r.purchases[purchaseId].ExpiresAt = sql.NullTime{Time: time.Now().Add(reservationTTL), Valid: true}
+	r.extended = append(r.extended, purchaseId)
+	return nil
+}
+
+func (r *fakePurchaseRepository) GetPurchasePayments(_ context.Context, purchaseId int) ([]entity.PurchasePayment, error) {
+	var payments []entity.PurchasePayment
+	for _, payment := range r.payments {
+		if payment.PurchaseID == purchaseId {
+			payments = append(payments, *payment)
+		}
+	}
+
+	slices.SortFunc(payments, func(a, b entity.PurchasePayment) int { return a.ID - b.ID })
+	return payments, nil
+}
+
+func (r *fakePurchaseRepository) UpdatePurchasePaymentStatus(_ context.Context, paymentId int, status string, note string) error {
+	r.payments[paymentId].Status = status
+	r.payments[paymentId].Note = note
+	return nil
+}
+
+func (r *fakePurchaseRepository) RefundPurchasePayment(_ context.Context, paymentId int, refundedBy int, reason string) error {
+	payment := r.payments[paymentId]
+	payment.Status = entity.PaymentStatusRefunded
+	payment.Note = reason
+	payment.RefundedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	payment.RefundedBy = sql.NullInt32{Int32: int32(refundedBy), Valid: true}
+	return nil
+}
+
+func (r *fakePurchaseRepository) MarkPaymentShipped(_ context.Context, paymentId int) error {
+	r.payments[paymentId].ShippedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *fakePurchaseRepository) CreatePaymentProofs(_ context.Context, paymentId int, fileIds []int) error {
+	r.proofs[paymentId] = append(r.proofs[paymentId], fileIds...)
+	return nil
+}
+
+func (r *fakePurchaseRepository) CommitReservedQuantity(_ context.Context, productId int, quantity int) error {
+	r.committed[productId] += quantity
+	return nil
+}
+
+func (r *fakePurchaseRepository) ReleaseReservedQuantity(_ context.Context, productId int, quantity int) error {
+	r.released[productId] += quantity
+	return nil
+}
+
+func (r *fakePurchaseRepository) RestoreReservedQuantity(_ context.Context, productId int, quantity int) error {
+	r.restored[productId] += quantity
+	return nil
+}
+
+func (r *fakePurchaseRepository) RestockQuantity(_ context.Context, productId int, quantity int) error {
+	r.restocked[productId] += quantity
+	return nil
+}
+
+func (r *fakePurchaseRepository) CreatePurchaseEvent(_ context.Context, event *entity.PurchaseEvent) error {
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// fakeFileService hands out any file the caller asks for
+type fakeFileService struct {
+	contracts.FileService
+}
+
+func (fakeFileService) GetOwnedFile(_ context.Context, _ int, fileID string) (*entity.File, error) {
+	id, err := strconv.Atoi(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.File{ID: id}, nil
+}
+
+// fakeVoucherService records which redemptions were given back
+type fakeVoucherService struct {
+	contracts.VoucherService
+
+	released       []int
+	sellerReleased []string
+}
+
+func (s *fakeVoucherService) ReleaseRedemptions(_ context.Context, purchaseID int) error {
+	s.released = append(s.released, purchaseID)
+	return nil
+}
+
+func (s *fakeVoucherService) ReleaseSellerRedemptions(_ context.Context, purchaseID int, sellerID int) error {
+	s.sellerReleased = append(s.sellerReleased, fmt.Sprintf("%d/%d", purchaseID, sellerID))
+	return nil
+}
+
+type purchaseFixture struct {
+	service  *purchaseService
+	repo     *fakePurchaseRepository
+	vouchers *fakeVoucherService
+	uow      *fakeUnitOfWork
+}
+
+func newPurchaseFixture() *purchaseFixture {
+	lookupTokens, err := signature.New("secret")
+	if err != nil {
+		panic(err)
+	}
+
+	f := &purchaseFixture{
+		repo:     newFakePurchaseRepository(),
+		vouchers: &fakeVoucherService{},
+		uow:      &fakeUnitOfWork{},
+	}
+
+	f.service = NewPurchaseService(
+		f.repo,
+		f.uow,
+		fakeFileService{},
+		f.vouchers,
+		validator.Validator,
+		lookupTokens,
+		time.Hour,
+		time.Hour,
+	).(*purchaseService)
+
+	return f
+}
+
+func idr(amount int64) money.Money {
+	return money.New(amount*100, "IDR")
+}
+
+// twoSellerPurchase is a purchase of one product from seller 10 and two of
+// another from seller 20, paid with one payment share per seller
+func twoSellerPurchase(status string, sellerStatus, otherSellerStatus string) (entity.Purchase, []entity.PurchasePayment) {
+	purchase := entity.Purchase{
+		ID:      1,
+		BuyerID: sql.NullInt32{Int32: 5, Valid: true},
+		Status:  status,
+		PurchasedItems: entity.PurchaseItems{
+			{ProductID: 100, SellerID: 10, Quantity: 2, Price: idr(50000)},
+			{ProductID: 200, SellerID: 20, Quantity: 3, Price: idr(20000)},
+		},
+	}
+
+	payments := []entity.PurchasePayment{
+		{ID: 11, SellerID: 10, Status: sellerStatus, Subtotal: idr(100000), Discount: idr(10000), VoucherCode: "TEN", TotalPrice: idr(90000)},
+		{ID: 21, SellerID: 20, Status: otherSellerStatus, Subtotal: idr(60000), TotalPrice: idr(60000)},
+	}
+
+	return purchase, payments
+}
+
+func TestUploadPayment(t *testing.T) {
+	tests := []struct {
+		name          string
+		expiresIn     time.Duration
+		sellerId      string
+		otherStatus   string
+		wantCode      int
+		wantStatus    string
+		wantCommitted map[int]int
+	}{
+		{
+			name:          "every seller at once",
+			expiresIn:     time.Hour,
+			otherStatus:   entity.PaymentStatusPending,
+			wantStatus:    entity.PurchaseStatusPaid,
+			wantCommitted: map[int]int{100: 2, 200: 3},
+		},
+		{
+			name:        "one of two sellers",
+			expiresIn:   time.Hour,
+			sellerId:    "10",
+			otherStatus: entity.PaymentStatusPending,
+			wantStatus:  entity.PurchaseStatusPending,
+		},
+		{
+			name:          "last seller",
+			expiresIn:     time.Hour,
+			sellerId:      "10",
+			otherStatus:   entity.PaymentStatusSubmitted,
+			wantStatus:    entity.PurchaseStatusPaid,
+			wantCommitted: map[int]int{100: 2, 200: 3},
+		},
+		{
+			name:        "expired reservation the sweeper hasn't released",
+			expiresIn:   -time.Second,
+			otherStatus: entity.PaymentStatusPending,
+			wantCode:    fiber.StatusConflict,
+		},
+		{
+			name:        "seller already paid",
+			expiresIn:   time.Hour,
+			sellerId:    "20",
+			otherStatus: entity.PaymentStatusSubmitted,
+			wantCode:    fiber.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPurchaseFixture()
+			purchase, payments := twoSellerPurchase(entity.PurchaseStatusPending, entity.PaymentStatusPending, tt.otherStatus)
+			purchase.ExpiresAt = sql.NullTime{Time: time.Now().Add(tt.expiresIn), Valid: true}
+			f.repo.addPurchase(purchase, payments...)
+
+			err := f.service.UploadPayment(context.Background(), 5, "1", "", dto.UploadPaymentRequest{FileIDs: []string{"7"}, SellerID: tt.sellerId})
+
+			if tt.wantCode != 0 {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) || fiberErr.Code != tt.wantCode {
+					t.Fatalf("UploadPayment() error = %v, want status %d", err, tt.wantCode)
+				}
+
+				if f.repo.payments[11].Status != entity.PaymentStatusPending || len(f.repo.proofs) != 0 {
+					t.Errorf("payment = %s with proofs %v, want it untouched", f.repo.payments[11].Status, f.repo.proofs)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("UploadPayment() error = %v", err)
+			}
+
+			if got := f.repo.payments[11].Status; got != entity.PaymentStatusSubmitted {
+				t.Errorf("payment status = %s, want %s", got, entity.PaymentStatusSubmitted)
+			}
+
+			if !slices.Equal(f.repo.proofs[11], []int{7}) {
+				t.Errorf("proofs = %v, want [7]", f.repo.proofs[11])
+			}
+
+			if got := f.repo.purchases[1].Status; got != tt.wantStatus {
+				t.Errorf("purchase status = %s, want %s", got, tt.wantStatus)
+			}
+
+			if len(f.repo.committed) != len(tt.wantCommitted) {
+				t.Fatalf("committed = %v, want %v", f.repo.committed, tt.wantCommitted)
+			}
+
+			for productId, quantity := range tt.wantCommitted {
+				if f.repo.committed[productId] != quantity {
+					t.Errorf("committed = %v, want %v", f.repo.committed, tt.wantCommitted)
+				}
+			}
+		})
+	}
+}
+
+func TestReleaseExpiredReservations(t *testing.T) {
+	f := newPurchaseFixture()
+	expired := sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+
+	add := func(id int, expiresAt sql.NullTime, sellerStatus string) {
+		purchase, payments := twoSellerPurchase(entity.PurchaseStatusPending, sellerStatus, entity.PaymentStatusPending)
+		purchase.ID = id
+		purchase.ExpiresAt = expiresAt
+		for i := range payments {
+			payments[i].ID = id*100 + i
+		}
+
+		f.repo.addPurchase(purchase, payments...)
+	}
+
+	add(1, expired, entity.PaymentStatusPending)
+	add(2, expired, entity.PaymentStatusRefunded)
+	add(3, expired, entity.PaymentStatusSubmitted)
+	add(4, sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}, entity.PaymentStatusPending)
+
+	// More than one batch
+	for id := 10; id < 10+expiredPurchasesBatchSize; id++ {
+		add(id, expired, entity.PaymentStatusPending)
+	}
+
+	released, err := f.service.ReleaseExpiredReservations(context.Background())
+	if err != nil {
+		t.Fatalf("ReleaseExpiredReservations() error = %v", err)
+	}
+
+	if want := 2 + expiredPurchasesBatchSize; released != want {
+		t.Errorf("ReleaseExpiredReservations() = %d, want %d", released, want)
+	}
+
+	if f.uow.committed != 2 {
+		t.Errorf("transactions = %d, want one per batch", f.uow.committed)
+	}
+
+	for id, want := range map[int]string{1: entity.PurchaseStatusExpired, 2: entity.PurchaseStatusExpired, 3: entity.PurchaseStatusPending, 4: entity.PurchaseStatusPending} {
+		if got := f.repo.purchases[id].Status; got != want {
+			t.Errorf("purchase %d status = %s, want %s", id, got, want)
+		}
+	}
+
+	// The refunded share of purchase 2 gave its 2 items back already
+	purchases := 1 + expiredPurchasesBatchSize
+	if f.repo.released[100] != 2*purchases || f.repo.released[200] != 3*(purchases+1) {
+		t.Errorf("released = %v, want %d and %d", f.repo.released, 2*purchases, 3*(purchases+1))
+	}
+
+	if len(f.vouchers.released) != released || slices.Contains(f.vouchers.released, 3) {
+		t.Errorf("purchase redemptions released = %v, want those of the expired purchases", f.vouchers.released)
+	}
+}
+
+func TestPurchaseReservesStock(t *testing.T) {
+	tests := []struct {
+		name         string
+		items        []dto.PurchaseItemRequest
+		wantCode     int
+		wantReserved map[int]int
+		wantPayments int
+	}{
+		{
+			name:         "items of two sellers",
+			items:        []dto.PurchaseItemRequest{{ProductID: "200", Qty: 3}, {ProductID: "100", Qty: 2}},
+			wantReserved: map[int]int{100: 2, 200: 3},
+			wantPayments: 2,
+		},
+		{
+			name:         "repeated product is merged",
+			items:        []dto.PurchaseItemRequest{{ProductID: "100", Qty: 2}, {ProductID: "100", Qty: 3}},
+			wantReserved: map[int]int{100: 5},
+			wantPayments: 1,
+		},
+		{
+			name:         "whole stock",
+			items:        []dto.PurchaseItemRequest{{ProductID: "100", Qty: 5}},
+			wantReserved: map[int]int{100: 5},
+			wantPayments: 1,
+		},
+		{
+			name:     "more than the stock",
+			items:    []dto.PurchaseItemRequest{{ProductID: "100", Qty: 6}},
+			wantCode: fiber.StatusConflict,
+		},
+		{
+			name:     "repeated product adding up to more than the stock",
+			items:    []dto.PurchaseItemRequest{{ProductID: "100", Qty: 3}, {ProductID: "100", Qty: 3}},
+			wantCode: fiber.StatusConflict,
+		},
+		{
+			name:     "unknown product",
+			items:    []dto.PurchaseItemRequest{{ProductID: "300", Qty: 2}},
+			wantCode: fiber.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPurchaseFixture()
+			f.repo.products[100] = &entity.Product{ID: 100, UserID: 10, Quantity: 5, Price: idr(50000)}
+			f.repo.products[200] = &entity.Product{ID: 200, UserID: 20, Quantity: 5, Price: idr(20000)}
+
+			res, err := f.service.Purchase(context.Background(), 5, dto.PurchaseRequest{
+				PurchasedItems:      tt.items,
+				SenderName:          "Buyer",
+				SenderContactType:   "email",
+				SenderContactDetail: "buyer@example.com",
+			})
+
+			if tt.wantCode != 0 {
+				var fiberErr *fiber.Error
+				if !errors.As(err, &fiberErr) || fiberErr.Code != tt.wantCode {
+					t.Fatalf("Purchase() error = %v, want status %d", err, tt.wantCode)
+				}
+
+				if f.uow.rolledBack != 1 || len(f.repo.purchases) != 0 {
+					t.Errorf("rolled back %d times with %d purchases, want the transaction rolled back", f.uow.rolledBack, len(f.repo.purchases))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Purchase() error = %v", err)
+			}
+
+			for productId, product := range f.repo.products {
+				if product.ReservedQuantity != tt.wantReserved[productId] || product.Quantity != 5-tt.wantReserved[productId] {
+					t.Errorf("product %d qty %d reserved %d, want %d reserved", productId, product.Quantity, product.ReservedQuantity, tt.wantReserved[productId])
+				}
+			}
+
+			purchase := f.repo.purchases[1]
+			if purchase.Status != entity.PurchaseStatusPending {
+				t.Errorf("purchase status = %s, want %s", purchase.Status, entity.PurchaseStatusPending)
+			}
+
+			if until := time.Until(purchase.ExpiresAt.Time); until <= 59*time.Minute || until > time.Hour {
+				t.Errorf("reservation expires in %s, want the reservation TTL", until)
+			}
+
+			if len(f.repo.payments) != tt.wantPayments || len(res.PaymentDetails) != tt.wantPayments {
+				t.Errorf("%d payments, %d in the response, want one per seller", len(f.repo.payments), len(res.PaymentDetails))
+			}
+
+			if len(f.repo.events) != 1 || f.repo.events[0].ToStatus != entity.PurchaseStatusPending {
+				t.Errorf("events = %+v, want the purchase created as pending", f.repo.events)
+			}
+
+			if !f.service.canAccess(*purchase, 0, res.LookupToken) {
+				t.Errorf("lookup token %q doesn't give access to the purchase", res.LookupToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// purchaseTransitions lists the statuses a purchase may move to from each status.
+// Statuses missing from the map are final.
+var purchaseTransitions = map[string][]string{
+	entity.PurchaseStatusPending: {
+		entity.PurchaseStatusPaid,
+		entity.PurchaseStatusCancelled,
+		entity.PurchaseStatusExpired,
+	},
+	entity.PurchaseStatusPaid: {
+		// a seller rejected the payment proof
+		entity.PurchaseStatusPending,
+		entity.PurchaseStatusConfirmed,
+		entity.PurchaseStatusCancelled,
+	},
+	entity.PurchaseStatusConfirmed: {
+		entity.PurchaseStatusShipped,
+		entity.PurchaseStatusCancelled,
+	},
+	entity.PurchaseStatusShipped: {
+		entity.PurchaseStatusCompleted,
+	},
+}
+
+func canTransition(from, to string) bool {
+	return slices.Contains(purchaseTransitions[from], to)
+}
+
+// transition moves the purchase to the given status and records the change in
+// the purchase history. actorUserId is 0 when the system makes the change.
+func (s *purchaseService) transition(ctx context.Context, purchase *entity.Purchase, to string, actorUserId int, note string) error {
+	if !canTransition(purchase.Status, to) {
+		return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("cannot change purchase status from %s to %s", purchase.Status, to))
+	}
+
+	err := s.repo.UpdatePurchaseStatus(ctx, purchase.ID, to)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	err = s.recordEvent(ctx, purchase.ID, purchase.Status, to, actorUserId, note)
+	if err != nil {
+		return err
+	}
+
+	purchase.Status = to
+	return nil
+}
+
+func (s *purchaseService) recordEvent(ctx context.Context, purchaseId int, from, to string, actorUserId int, note string) error {
+	event := &entity.PurchaseEvent{
+		PurchaseID:  purchaseId,
+		FromStatus:  sql.NullString{String: from, Valid: from != ""},
+		ToStatus:    to,
+		ActorUserID: sql.NullInt32{Int32: int32(actorUserId), Valid: actorUserId != 0},
+		Note:        note,
+	}
+
+	err := s.repo.CreatePurchaseEvent(ctx, event)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{from: entity.PurchaseStatusPending, to: entity.PurchaseStatusPaid, want: true},
+		{from: entity.PurchaseStatusPending, to: entity.PurchaseStatusCancelled, want: true},
+		{from: entity.PurchaseStatusPending, to: entity.PurchaseStatusExpired, want: true},
+		{from: entity.PurchaseStatusPending, to: entity.PurchaseStatusConfirmed, want: false},
+		{from: entity.PurchaseStatusPending, to: entity.PurchaseStatusShipped, want: false},
+		{from: entity.PurchaseStatusPending, to: entity.PurchaseStatusPending, want: false},
+		{from: entity.PurchaseStatusPaid, to: entity.PurchaseStatusPending, want: true},
+		{from: entity.PurchaseStatusPaid, to: entity.PurchaseStatusConfirmed, want: true},
+		{from: entity.PurchaseStatusPaid, to: entity.PurchaseStatusCancelled, want: true},
+		{from: entity.PurchaseStatusPaid, to: entity.PurchaseStatusPaid, want: false},
+		{from: entity.PurchaseStatusPaid, to: entity.PurchaseStatusShipped, want: false},
+		{from: entity.PurchaseStatusPaid, to: entity.PurchaseStatusExpired, want: false},
+		{from: entity.PurchaseStatusConfirmed, to: entity.PurchaseStatusShipped, want: true},
+		{from: entity.PurchaseStatusConfirmed, to: entity.PurchaseStatusCancelled, want: true},
+		{from: entity.PurchaseStatusConfirmed, to: entity.PurchaseStatusCompleted, want: false},
+		{from: entity.PurchaseStatusConfirmed, to: entity.PurchaseStatusPending, want: false},
+		{from: entity.PurchaseStatusShipped, to: entity.PurchaseStatusCompleted, want: true},
+		{from: entity.PurchaseStatusShipped, to: entity.PurchaseStatusCancelled, want: false},
+		{from: entity.PurchaseStatusCompleted, to: entity.PurchaseStatusCancelled, want: false},
+		{from: entity.PurchaseStatusCancelled, to: entity.PurchaseStatusPending, want: false},
+		{from: entity.PurchaseStatusExpired, to: entity.PurchaseStatusPaid, want: false},
+		{from: "unknown", to: entity.PurchaseStatusPaid, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			got := canTransition(tt.from, tt.to)
+			if got != tt.want {
+				t.Errorf("canTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurchaseTransitionsFinalStatuses(t *testing.T) {
+	finals := []string{
+		entity.PurchaseStatusCompleted,
+		entity.PurchaseStatusCancelled,
+		entity.PurchaseStatusExpired,
+	}
+
+	for _, status := range finals {
+		t.Run(status, func(t *testing.T) {
+			if next, ok := purchaseTransitions[status]; ok {
+				t.Errorf("final status %q moves on to %v", status, next)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+)
+
+type sessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) contracts.SessionRepository {
+	return &sessionRepository{db}
+}
+
+// Create implements contracts.SessionRepository.
+func (r *sessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx,
+		"INSERT INTO sessions (id, user_id, user_agent, ip_address) VALUES ($1, $2, $3, $4) RETURNING created_at, last_used_at",
+		session.ID, session.UserID, session.UserAgent, session.IPAddress,
+	).Scan(&session.CreatedAt, &session.LastUsedAt)
+	return database.MapError(err)
+}
+
+// FindByID implements contracts.SessionRepository.
+func (r *sessionRepository) FindByID(ctx context.Context, id string) (*entity.Session, error) {
+	var session entity.Session
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &session, "SELECT * FROM sessions WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// FindActiveByUserID implements contracts.SessionRepository.
+func (r *sessionRepository) FindActiveByUserID(ctx context.Context, userID int) ([]entity.Session, error) {
+	var sessions []entity.Session
+	err := sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &sessions,
+		"SELECT * FROM sessions WHERE user_id = $1 AND revoked_at IS NULL ORDER BY last_used_at DESC",
+		userID,
+	)
+	return sessions, err
+}
+
+// IsActive implements contracts.SessionRepository.
+func (r *sessionRepository) IsActive(ctx context.Context, id string) (bool, error) {
+	var active bool
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &active, "SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL)", id)
+	return active, err
+}
+
+// Touch implements contracts.SessionRepository.
+func (r *sessionRepository) Touch(ctx context.Context, id string, ipAddress string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE sessions SET last_used_at = CURRENT_TIMESTAMP, ip_address = $1 WHERE id = $2", ipAddress, id)
+	return database.MapError(err)
+}
+
+// Revoke implements contracts.SessionRepository.
+func (r *sessionRepository) Revoke(ctx context.Context, id string, reason string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $1 WHERE id = $2 AND revoked_at IS NULL",
+		reason, id,
+	)
+	return database.MapError(err)
+}
+
+// RevokeByUserID implements contracts.SessionRepository.
+func (r *sessionRepository) RevokeByUserID(ctx context.Context, userID int, keepID string, reason string) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP, revoked_reason = $1 WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL",
+		reason, userID, keepID,
+	)
+	return database.MapError(err)
+}
+
+// CreateRefreshToken implements contracts.SessionRepository.
+func (r *sessionRepository) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken, ttl time.Duration) error {
+	err := database.Conn(ctx, r.db).QueryRowxContext(ctx, `
+		INSERT INTO refresh_tokens (session_id, token_hash, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')
+		RETURNING id, expires_at, created_at
+	`, token.SessionID, token.TokenHash, ttl.Seconds()).Scan(&token.ID, &token.ExpiresAt, &token.CreatedAt)
+	return database.MapError(err)
+}
+
+// LockRefreshToken implements contracts.SessionRepository.
+func (r *sessionRepository) LockRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &token,
+		"SELECT * FROM refresh_tokens WHERE token_hash = $1 AND expires_at > CURRENT_TIMESTAMP FOR UPDATE",
+		tokenHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed implements contracts.SessionRepository.
+func (r *sessionRepository) MarkRefreshTokenUsed(ctx context.Context, id int) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, "UPDATE refresh_tokens SET used_at = CURRENT_TIMESTAMP WHERE id = $1", id)
+	return database.MapError(err)
+}
@@ -21,8 +21,12 @@ func InitUserController(router fiber.Router, service contracts.UserService, midd
 
 	userRouter.Get("/", middleware.RequireAuth(), controller.getUser)
 	userRouter.Put("/", middleware.RequireAuth(), controller.updateUser)
+	userRouter.Put("/password", middleware.RequireAuth(), controller.changePassword)
 	userRouter.Post("/link/email", middleware.RequireAuth(), controller.linkEmail)
 	userRouter.Post("/link/phone", middleware.RequireAuth(), controller.linkPhone)
+	userRouter.Get("/sessions", middleware.RequireAuth(), controller.getSessions)
+	userRouter.Delete("/sessions/others", middleware.RequireAuth(), controller.revokeOtherSessions)
+	userRouter.Delete("/sessions/:sessionId", middleware.RequireAuth(), controller.revokeSession)
 }
 
 func (c *userController) getUser(ctx *fiber.Ctx) error {
@@ -52,6 +56,22 @@ func (c *userController) updateUser(ctx *fiber.Ctx) error {
 	return ctx.Status(fiber.StatusOK).JSON(res)
 }
 
+func (c *userController) changePassword(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(jwt.Claims)
+
+	var req dto.ChangePasswordRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err := c.service.ChangePassword(ctx.Context(), claims.UserID, claims.ID, &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
 func (c *userController) linkEmail(ctx *fiber.Ctx) error {
 	userID := ctx.Locals("claims").(jwt.Claims).UserID
 
@@ -83,3 +103,36 @@ func (c *userController) linkPhone(ctx *fiber.Ctx) error {
 
 	return ctx.Status(fiber.StatusOK).JSON(res)
 }
+
+func (c *userController) getSessions(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(jwt.Claims)
+
+	res, err := c.service.GetSessions(ctx.Context(), claims.UserID, claims.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *userController) revokeSession(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("claims").(jwt.Claims).UserID
+
+	err := c.service.RevokeSession(ctx.Context(), userID, ctx.Params("sessionId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (c *userController) revokeOtherSessions(ctx *fiber.Ctx) error {
+	claims := ctx.Locals("claims").(jwt.Claims)
+
+	err := c.service.RevokeOtherSessions(ctx.Context(), claims.UserID, claims.ID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -2,10 +2,12 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
 )
 
 type userRepository struct {
@@ -18,7 +20,7 @@ func NewUserRepository(db *sqlx.DB) contracts.UserRepository {
 
 func (u *userRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	err := u.db.GetContext(ctx, &user, "SELECT * FROM users WHERE email = ?", email)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, u.db), &user, "SELECT * FROM users WHERE email = $1", email)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +31,7 @@ func (u *userRepository) FindByEmail(ctx context.Context, email string) (*entity
 // FindByEmailOrPhone implements contracts.UserRepository.
 func (u *userRepository) FindByEmailOrPhone(ctx context.Context, email string, phone string) (*entity.User, error) {
 	var user entity.User
-	err := u.db.GetContext(ctx, &user, "SELECT * FROM users WHERE email = ? OR phone = ?", email, phone)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, u.db), &user, "SELECT * FROM users WHERE email = $1 OR phone = $2", email, phone)
 	if err != nil {
 		return nil, err
 	}
@@ -40,7 +42,7 @@ func (u *userRepository) FindByEmailOrPhone(ctx context.Context, email string, p
 // FindByID implements contracts.UserRepository.
 func (u *userRepository) FindByID(ctx context.Context, id int) (*entity.User, error) {
 	var user entity.User
-	err := u.db.GetContext(ctx, &user, "SELECT * FROM users WHERE id = ?", id)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, u.db), &user, "SELECT * FROM users WHERE id = $1", id)
 	if err != nil {
 		return nil, err
 	}
@@ -51,7 +53,7 @@ func (u *userRepository) FindByID(ctx context.Context, id int) (*entity.User, er
 // FindByPhone implements contracts.UserRepository.
 func (u *userRepository) FindByPhone(ctx context.Context, phone string) (*entity.User, error) {
 	var user entity.User
-	err := u.db.GetContext(ctx, &user, "SELECT * FROM users WHERE phone = ?", phone)
+	err := sqlx.GetContext(ctx, database.Conn(ctx, u.db), &user, "SELECT * FROM users WHERE phone = $1", phone)
 	if err != nil {
 		return nil, err
 	}
@@ -59,18 +61,36 @@ func (u *userRepository) FindByPhone(ctx context.Context, phone string) (*entity
 	return &user, nil
 }
 
-// Update implements contracts.UserRepository.
+// Update implements contracts.UserRepository. A changed email or phone has to
+// be verified again.
 func (u *userRepository) Update(ctx context.Context, user *entity.User) error {
-	_, err := u.db.NamedExecContext(ctx, `
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, u.db), `
 		UPDATE users
 		SET email = :email, phone = :phone, password = :password,
+			email_verified_at = CASE WHEN email IS DISTINCT FROM :email THEN NULL ELSE email_verified_at END,
+			phone_verified_at = CASE WHEN phone IS DISTINCT FROM :phone THEN NULL ELSE phone_verified_at END,
 			bank_account_number = :bank_account_number, bank_account_name = :bank_account_name, bank_account_holder = :bank_account_holder,
 			file_id = :file_id, file_uri = :file_uri, file_thumbnail_uri = :file_thumbnail_uri
 		WHERE id = :id
+		RETURNING email_verified_at, phone_verified_at
 	`, user)
 	if err != nil {
-		return err
+		return database.MapError(err)
 	}
+	defer rows.Close()
 
-	return nil
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return database.MapError(err)
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&user.EmailVerifiedAt, &user.PhoneVerifiedAt)
+}
+
+// UpdatePassword implements contracts.UserRepository.
+func (u *userRepository) UpdatePassword(ctx context.Context, userID int, password string) error {
+	_, err := database.Conn(ctx, u.db).ExecContext(ctx, "UPDATE users SET password = $1 WHERE id = $2", password, userID)
+	return err
 }
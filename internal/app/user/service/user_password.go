@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// ChangePassword implements contracts.UserService.
+func (u *userService) ChangePassword(ctx context.Context, id int, currentSessionID string, req *dto.ChangePasswordRequest) error {
+	valErr := u.validator.Validate(req)
+	if valErr != nil {
+		return fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	user, err := u.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fiber.NewError(fiber.StatusNotFound, "user not found")
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	// Guessing the current password with a stolen session is throttled like
+	// guessing it at login
+	limiterKey := "user:" + strconv.Itoa(user.ID)
+	wait, err := u.limiter.Blocked(ctx, limiterKey)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if wait > 0 {
+		return domain.ErrTooManyLoginAttempts
+	}
+
+	if !u.bcrypt.Compare(req.CurrentPassword, user.Password) {
+		_, err := u.limiter.Fail(ctx, limiterKey)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return domain.ErrInvalidCurrentPassword
+	}
+
+	err = u.limiter.Reset(ctx, limiterKey)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	hashedPassword, err := u.bcrypt.Hash(req.NewPassword)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return u.uow.Do(ctx, func(ctx context.Context) error {
+		err := u.repo.UpdatePassword(ctx, user.ID, hashedPassword)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if !req.RevokeOtherSessions {
+			return nil
+		}
+
+		err = u.sessionRepo.RevokeByUserID(ctx, id, currentSessionID, entity.SessionRevokedPasswordChanged)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		return nil
+	})
+}
@@ -7,20 +7,44 @@ import (
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/bcrypt"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
 )
 
 type userService struct {
-	repo      contracts.UserRepository
-	validator validator.ValidatorInterface
+	repo        contracts.UserRepository
+	sessionRepo contracts.SessionRepository
+	otpService  contracts.OneTimeCodeService
+	fileService contracts.FileService
+	limiter     contracts.LoginLimiter
+	uow         contracts.UnitOfWork
+	validator   validator.ValidatorInterface
+	bcrypt      bcrypt.BcryptInterface
 }
 
-func NewUserService(repo contracts.UserRepository, validator validator.ValidatorInterface) contracts.UserService {
+func NewUserService(
+	repo contracts.UserRepository,
+	sessionRepo contracts.SessionRepository,
+	otpService contracts.OneTimeCodeService,
+	fileService contracts.FileService,
+	limiter contracts.LoginLimiter,
+	uow contracts.UnitOfWork,
+	validator validator.ValidatorInterface,
+	bcrypt bcrypt.BcryptInterface,
+) contracts.UserService {
 	return &userService{
 		repo,
+		sessionRepo,
+		otpService,
+		fileService,
+		limiter,
+		uow,
 		validator,
+		bcrypt,
 	}
 }
 
@@ -50,7 +74,7 @@ func (u *userService) GetUser(ctx context.Context, id int) (*dto.GetUserResponse
 		}(),
 		FileID: func() string {
 			if user.FileID.Valid {
-				return strconv.Itoa(int(user.FileID.Int16))
+				return strconv.Itoa(int(user.FileID.Int32))
 			}
 			return ""
 		}(),
@@ -84,6 +108,8 @@ func (u *userService) GetUser(ctx context.Context, id int) (*dto.GetUserResponse
 			}
 			return ""
 		}(),
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		PhoneVerified: user.PhoneVerifiedAt.Valid,
 	}
 
 	return res, nil
@@ -105,6 +131,22 @@ func (u *userService) LinkEmail(ctx context.Context, id int, req *dto.LinkEmailR
 		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
+	// An unverified holder whose code ran out doesn't keep the email from its
+	// actual owner
+	err = u.otpService.ReleaseUnverified(ctx, entity.OneTimeCodeVerifyEmail, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := u.repo.FindByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if err == nil && owner.ID != user.ID {
+		return nil, domain.ErrUserEmailAlreadyExists
+	}
+
 	user.Email = sql.NullString{
 		String: req.Email,
 		Valid:  true,
@@ -112,9 +154,19 @@ func (u *userService) LinkEmail(ctx context.Context, id int, req *dto.LinkEmailR
 
 	err = u.repo.Update(ctx, user)
 	if err != nil {
+		// The contact may have been taken since it was checked
+		if domain.IsRequestError(err) {
+			return nil, err
+		}
+
 		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
+	err = u.sendVerification(ctx, user, entity.OneTimeCodeVerifyEmail, dto.NotificationChannelEmail, req.Email)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &dto.LinkEmailResponse{
 		Email: func() string {
 			if user.Email.Valid {
@@ -130,7 +182,7 @@ func (u *userService) LinkEmail(ctx context.Context, id int, req *dto.LinkEmailR
 		}(),
 		FileID: func() string {
 			if user.FileID.Valid {
-				return strconv.Itoa(int(user.FileID.Int16))
+				return strconv.Itoa(int(user.FileID.Int32))
 			}
 			return ""
 		}(),
@@ -185,6 +237,22 @@ func (u *userService) LinkPhone(ctx context.Context, id int, req *dto.LinkPhoneR
 		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
+	// An unverified holder whose code ran out doesn't keep the phone from its
+	// actual owner
+	err = u.otpService.ReleaseUnverified(ctx, entity.OneTimeCodeVerifyPhone, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := u.repo.FindByPhone(ctx, req.Phone)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if err == nil && owner.ID != user.ID {
+		return nil, domain.ErrUserPhoneAlreadyExists
+	}
+
 	user.Phone = sql.NullString{
 		String: req.Phone,
 		Valid:  true,
@@ -192,9 +260,19 @@ func (u *userService) LinkPhone(ctx context.Context, id int, req *dto.LinkPhoneR
 
 	err = u.repo.Update(ctx, user)
 	if err != nil {
+		// The contact may have been taken since it was checked
+		if domain.IsRequestError(err) {
+			return nil, err
+		}
+
 		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
+	err = u.sendVerification(ctx, user, entity.OneTimeCodeVerifyPhone, dto.NotificationChannelPhone, req.Phone)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &dto.LinkPhoneResponse{
 		Email: func() string {
 			if user.Email.Valid {
@@ -210,7 +288,7 @@ func (u *userService) LinkPhone(ctx context.Context, id int, req *dto.LinkPhoneR
 		}(),
 		FileID: func() string {
 			if user.FileID.Valid {
-				return strconv.Itoa(int(user.FileID.Int16))
+				return strconv.Itoa(int(user.FileID.Int32))
 			}
 			return ""
 		}(),
@@ -270,15 +348,23 @@ func (u *userService) UpdateUser(ctx context.Context, id int, req *dto.UpdateUse
 	}
 
 	if *req.FileID != "" {
-		fileID, err := strconv.Atoi(*req.FileID)
+		file, err := u.fileService.GetOwnedFile(ctx, id, *req.FileID)
 		if err != nil {
-			return nil, fiber.NewError(fiber.StatusBadRequest, "fileId must be a number")
+			return nil, err
 		}
 
-		user.FileID = sql.NullInt16{
-			Int16: int16(fileID),
+		user.FileID = sql.NullInt32{
+			Int32: int32(file.ID),
 			Valid: true,
 		}
+		user.FileURI = sql.NullString{
+			String: file.FileURI,
+			Valid:  true,
+		}
+		user.FileThumbnailURI = sql.NullString{
+			String: file.FileThumbnailURI,
+			Valid:  true,
+		}
 	}
 
 	user.BankAccountHolder = sql.NullString{
@@ -296,6 +382,10 @@ func (u *userService) UpdateUser(ctx context.Context, id int, req *dto.UpdateUse
 
 	err = u.repo.Update(ctx, user)
 	if err != nil {
+		if domain.IsRequestError(err) {
+			return nil, err
+		}
+
 		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -314,7 +404,7 @@ func (u *userService) UpdateUser(ctx context.Context, id int, req *dto.UpdateUse
 		}(),
 		FileID: func() string {
 			if user.FileID.Valid {
-				return strconv.Itoa(int(user.FileID.Int16))
+				return strconv.Itoa(int(user.FileID.Int32))
 			}
 			return ""
 		}(),
@@ -352,3 +442,26 @@ func (u *userService) UpdateUser(ctx context.Context, id int, req *dto.UpdateUse
 
 	return res, nil
 }
+
+// sendVerification sends a code to a contact the user just linked, unless it
+// was linked before and is verified already. Codes sent for the previous
+// contact stop working since they were sent to another target.
+func (u *userService) sendVerification(ctx context.Context, user *entity.User, purpose string, channel string, target string) error {
+	verified := user.EmailVerifiedAt.Valid
+	if channel == dto.NotificationChannelPhone {
+		verified = user.PhoneVerifiedAt.Valid
+	}
+
+	if verified {
+		return nil
+	}
+
+	err := u.otpService.Issue(ctx, user.ID, purpose, channel, target)
+	if err != nil && !errors.Is(err, domain.ErrOneTimeCodeTooSoon) {
+		return err
+	}
+
+	// A code that couldn't be sent yet can be asked for again through
+	// /auth/verify/send
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+)
+
+// GetSessions implements contracts.UserService.
+func (u *userService) GetSessions(ctx context.Context, id int, currentSessionID string) ([]dto.SessionResponse, error) {
+	sessions, err := u.sessionRepo.FindActiveByUserID(ctx, id)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		res = append(res, dto.SessionResponse{
+			SessionID:  session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			Current:    session.ID == currentSessionID,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastUsedAt: session.LastUsedAt.Format(time.RFC3339),
+		})
+	}
+
+	return res, nil
+}
+
+// RevokeSession implements contracts.UserService. Revoking the current
+// session logs the user out.
+func (u *userService) RevokeSession(ctx context.Context, id int, sessionID string) error {
+	session, err := u.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrSessionNotFound
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if session.UserID != id || session.RevokedAt.Valid {
+		return domain.ErrSessionNotFound
+	}
+
+	err = u.sessionRepo.Revoke(ctx, session.ID, entity.SessionRevokedByUser)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions implements contracts.UserService.
+func (u *userService) RevokeOtherSessions(ctx context.Context, id int, currentSessionID string) error {
+	err := u.sessionRepo.RevokeByUserID(ctx, id, currentSessionID, entity.SessionRevokedByUser)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
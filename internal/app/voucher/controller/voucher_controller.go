@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+)
+
+type voucherController struct {
+	service contracts.VoucherService
+}
+
+func InitVoucherController(router fiber.Router, service contracts.VoucherService, middleware *middlewares.Middleware) {
+	controller := &voucherController{
+		service,
+	}
+
+	voucherRouter := router.Group("/voucher", middleware.RequireAuth())
+
+	voucherRouter.Get("/", controller.getVouchers)
+	voucherRouter.Post("/", controller.createVoucher)
+	voucherRouter.Delete("/:voucherId", controller.deleteVoucher)
+}
+
+func (c *voucherController) getVouchers(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var query dto.PaginationQuery
+	if err := ctx.QueryParser(&query); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.GetVouchers(ctx.Context(), userID, &query)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(res)
+}
+
+func (c *voucherController) createVoucher(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("claims").(jwt.Claims).UserID
+
+	var req dto.CreateVoucherRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	res, err := c.service.CreateVoucher(ctx.Context(), userID, &req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(res)
+}
+
+func (c *voucherController) deleteVoucher(ctx *fiber.Ctx) error {
+	userID := ctx.Locals("claims").(jwt.Claims).UserID
+
+	err := c.service.DeleteVoucher(ctx.Context(), userID, ctx.Params("voucherId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusOK)
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+)
+
+type voucherRepository struct {
+	db *sqlx.DB
+}
+
+func NewVoucherRepository(db *sqlx.DB) contracts.VoucherRepository {
+	return &voucherRepository{db}
+}
+
+// FindBySeller implements contracts.VoucherRepository.
+func (r *voucherRepository) FindBySeller(ctx context.Context, sellerID int, query *dto.PaginationQuery) ([]entity.Voucher, int, error) {
+	var total int
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &total, "SELECT COUNT(*) FROM vouchers WHERE seller_id = $1", sellerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	vouchers := []entity.Voucher{}
+	err = sqlx.SelectContext(ctx, database.Conn(ctx, r.db), &vouchers, `
+		SELECT * FROM vouchers
+		WHERE seller_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`, sellerID, query.Limit, query.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return vouchers, total, nil
+}
+
+// FindByID implements contracts.VoucherRepository.
+func (r *voucherRepository) FindByID(ctx context.Context, id int) (*entity.Voucher, error) {
+	var voucher entity.Voucher
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &voucher, "SELECT * FROM vouchers WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &voucher, nil
+}
+
+// LockByCode implements contracts.VoucherRepository. The voucher stays locked
+// until the transaction ends, so its usage limits can't be overrun.
+func (r *voucherRepository) LockByCode(ctx context.Context, code string) (*entity.Voucher, error) {
+	var voucher entity.Voucher
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &voucher, "SELECT * FROM vouchers WHERE code = $1 FOR UPDATE", code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &voucher, nil
+}
+
+// ExistsByCode implements contracts.VoucherRepository.
+func (r *voucherRepository) ExistsByCode(ctx context.Context, code string) (bool, error) {
+	var exists bool
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &exists, "SELECT EXISTS (SELECT 1 FROM vouchers WHERE code = $1)", code)
+	return exists, err
+}
+
+// Create implements contracts.VoucherRepository.
+func (r *voucherRepository) Create(ctx context.Context, voucher *entity.Voucher) error {
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), `
+		INSERT INTO vouchers (code, seller_id, discount_type, percent_off, amount_off, max_discount, min_spend,
+			category, usage_limit, per_user_limit, starts_at, ends_at)
+		VALUES (:code, :seller_id, :discount_type, :percent_off, :amount_off, :max_discount, :min_spend,
+			:category, :usage_limit, :per_user_limit, :starts_at, :ends_at)
+		RETURNING id, created_at, updated_at
+	`, voucher)
+	if err != nil {
+		return database.MapError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return database.MapError(err)
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&voucher.ID, &voucher.CreatedAt, &voucher.UpdatedAt)
+}
+
+// Delete implements contracts.VoucherRepository.
+func (r *voucherRepository) Delete(ctx context.Context, id int) error {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, "DELETE FROM vouchers WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+// CountRedemptions implements contracts.VoucherRepository.
+func (r *voucherRepository) CountRedemptions(ctx context.Context, voucherID int, buyerID int) (int, error) {
+	var count int
+	err := sqlx.GetContext(ctx, database.Conn(ctx, r.db), &count, `
+		SELECT COUNT(*) FROM voucher_redemptions
+		WHERE voucher_id = $1 AND buyer_id = $2
+	`, voucherID, buyerID)
+	return count, err
+}
+
+// CreateRedemption implements contracts.VoucherRepository.
+func (r *voucherRepository) CreateRedemption(ctx context.Context, redemption *entity.VoucherRedemption) error {
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), `
+		WITH used AS (
+			UPDATE vouchers SET used_count = used_count + 1, updated_at = CURRENT_TIMESTAMP WHERE id = :voucher_id
+		)
+		INSERT INTO voucher_redemptions (voucher_id, purchase_id, buyer_id, buyer_contact, discount)
+		VALUES (:voucher_id, :purchase_id, :buyer_id, :buyer_contact, :discount)
+		RETURNING id, created_at
+	`, redemption)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+
+	return rows.Scan(&redemption.ID, &redemption.CreatedAt)
+}
+
+// DeleteRedemptions implements contracts.VoucherRepository.
+func (r *voucherRepository) DeleteRedemptions(ctx context.Context, purchaseID int) error {
+	return r.deleteRedemptions(ctx, "purchase_id = $1", purchaseID)
+}
+
+// DeleteSellerRedemptions implements contracts.VoucherRepository.
+func (r *voucherRepository) DeleteSellerRedemptions(ctx context.Context, purchaseID int, sellerID int) error {
+	return r.deleteRedemptions(ctx, "purchase_id = $1 AND voucher_id IN (SELECT id FROM vouchers WHERE seller_id = $2)", purchaseID, sellerID)
+}
+
+// deleteRedemptions deletes the redemptions matching the condition and gives
+// their uses back to the vouchers
+func (r *voucherRepository) deleteRedemptions(ctx context.Context, condition string, args ...any) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, fmt.Sprintf(`
+		WITH released AS (
+			DELETE FROM voucher_redemptions WHERE %s RETURNING voucher_id
+		)
+		UPDATE vouchers SET used_count = GREATEST(used_count - freed.count, 0), updated_at = CURRENT_TIMESTAMP
+		FROM (SELECT voucher_id, COUNT(*) AS count FROM released GROUP BY voucher_id) AS freed
+		WHERE vouchers.id = freed.voucher_id
+	`, condition), args...)
+	if err != nil {
+		return err
+	}
+	return nil
+}
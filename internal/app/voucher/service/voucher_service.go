@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+type voucherService struct {
+	repo      contracts.VoucherRepository
+	validator validator.ValidatorInterface
+}
+
+func NewVoucherService(repo contracts.VoucherRepository, validator validator.ValidatorInterface) contracts.VoucherService {
+	return &voucherService{
+		repo,
+		validator,
+	}
+}
+
+// GetVouchers implements contracts.VoucherService.
+func (s *voucherService) GetVouchers(ctx context.Context, sellerID int, query *dto.PaginationQuery) (*dto.PaginatedResponse[dto.VoucherResponse], error) {
+	valErr := s.validator.Validate(query)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	query.Normalize()
+
+	vouchers, total, err := s.repo.FindBySeller(ctx, sellerID, query)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	data := make([]dto.VoucherResponse, 0, len(vouchers))
+	for _, voucher := range vouchers {
+		data = append(data, toVoucherResponse(&voucher))
+	}
+
+	return &dto.PaginatedResponse[dto.VoucherResponse]{
+		Data: data,
+		Meta: dto.PaginationMeta{
+			Limit:  query.Limit,
+			Offset: query.Offset,
+			Total:  total,
+		},
+	}, nil
+}
+
+// CreateVoucher implements contracts.VoucherService.
+func (s *voucherService) CreateVoucher(ctx context.Context, sellerID int, req *dto.CreateVoucherRequest) (*dto.VoucherResponse, error) {
+	valErr := s.validator.Validate(req)
+	if valErr != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, valErr.Error())
+	}
+
+	code := strings.ToUpper(req.Code)
+
+	exists, err := s.repo.ExistsByCode(ctx, code)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if exists {
+		return nil, domain.ErrVoucherCodeAlreadyExists
+	}
+
+	voucher := &entity.Voucher{
+		Code:         code,
+		SellerID:     sellerID,
+		DiscountType: req.DiscountType,
+		AmountOff:    money.New(0, money.DefaultCurrency),
+		MaxDiscount:  req.MaxDiscount,
+		MinSpend:     req.MinSpend,
+		Category:     sql.NullInt32{Int32: int32(req.Category), Valid: req.Category != 0},
+		UsageLimit:   sql.NullInt32{Int32: int32(req.UsageLimit), Valid: req.UsageLimit != 0},
+		PerUserLimit: sql.NullInt32{Int32: int32(req.PerUserLimit), Valid: req.PerUserLimit != 0},
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+	}
+
+	if req.DiscountType == entity.VoucherDiscountPercentage {
+		voucher.PercentOff = req.PercentOff
+	} else {
+		voucher.AmountOff = req.AmountOff
+	}
+
+	err = s.repo.Create(ctx, voucher)
+	if err != nil {
+		// Another seller may have taken the code since it was checked
+		if domain.IsRequestError(err) {
+			return nil, err
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	res := toVoucherResponse(voucher)
+
+	return &res, nil
+}
+
+// DeleteVoucher implements contracts.VoucherService.
+func (s *voucherService) DeleteVoucher(ctx context.Context, sellerID int, voucherID string) error {
+	id, err := strconv.Atoi(voucherID)
+	if err != nil {
+		return domain.ErrVoucherNotFound
+	}
+
+	voucher, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrVoucherNotFound
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if voucher.SellerID != sellerID {
+		return domain.ErrVoucherNotOwned
+	}
+
+	err = s.repo.Delete(ctx, voucher.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrVoucherNotFound
+		}
+
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// Redeem implements contracts.VoucherService.
+func (s *voucherService) Redeem(ctx context.Context, req *dto.RedeemVoucherRequest) (*dto.DiscountLine, error) {
+	voucher, err := s.repo.LockByCode(ctx, strings.ToUpper(req.Code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrVoucherNotFound
+		}
+
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	now := time.Now()
+	if now.Before(voucher.StartsAt) || !now.Before(voucher.EndsAt) {
+		return nil, domain.ErrVoucherNotActive
+	}
+
+	if voucher.UsageLimit.Valid && voucher.UsedCount >= int(voucher.UsageLimit.Int32) {
+		return nil, domain.ErrVoucherUsageLimitReached
+	}
+
+	// A guest can't be told apart from another one reliably, so vouchers
+	// limited per user are for logged in buyers only
+	if voucher.PerUserLimit.Valid {
+		if req.BuyerID == 0 {
+			return nil, domain.ErrVoucherLoginRequired
+		}
+
+		used, err := s.repo.CountRedemptions(ctx, voucher.ID, req.BuyerID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+
+		if used >= int(voucher.PerUserLimit.Int32) {
+			return nil, domain.ErrVoucherUsageLimitReached
+		}
+	}
+
+	discount, err := voucherDiscount(voucher, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repo.CreateRedemption(ctx, &entity.VoucherRedemption{
+		VoucherID:    voucher.ID,
+		PurchaseID:   req.PurchaseID,
+		BuyerID:      sql.NullInt32{Int32: int32(req.BuyerID), Valid: req.BuyerID != 0},
+		BuyerContact: req.BuyerContact,
+		Discount:     discount,
+	})
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return &dto.DiscountLine{
+		VoucherCode: voucher.Code,
+		SellerID:    strconv.Itoa(voucher.SellerID),
+		Amount:      discount,
+	}, nil
+}
+
+// ReleaseRedemptions implements contracts.VoucherService.
+func (s *voucherService) ReleaseRedemptions(ctx context.Context, purchaseID int) error {
+	err := s.repo.DeleteRedemptions(ctx, purchaseID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// ReleaseSellerRedemptions implements contracts.VoucherService.
+func (s *voucherService) ReleaseSellerRedemptions(ctx context.Context, purchaseID int, sellerID int) error {
+	err := s.repo.DeleteSellerRedemptions(ctx, purchaseID, sellerID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// voucherDiscount computes the discount on the items the voucher applies to,
+// which never exceeds what those items cost
+func voucherDiscount(voucher *entity.Voucher, items []entity.PurchaseItem) (money.Money, error) {
+	var eligible money.Money
+	applies := false
+	for _, item := range items {
+		if item.SellerID != voucher.SellerID {
+			continue
+		}
+
+		if voucher.Category.Valid && item.Category != int(voucher.Category.Int32) {
+			continue
+		}
+
+		applies = true
+		eligible = eligible.Add(item.Price.Mul(int64(item.Quantity)))
+	}
+
+	if !applies {
+		return money.Money{}, domain.ErrVoucherNotApplicable
+	}
+
+	if eligible.Cmp(voucher.MinSpend) < 0 {
+		return money.Money{}, domain.ErrVoucherMinSpendNotMet
+	}
+
+	discount := voucher.AmountOff
+	if voucher.DiscountType == entity.VoucherDiscountPercentage {
+		discount = eligible.Percent(int64(voucher.PercentOff))
+
+		if !voucher.MaxDiscount.IsZero() && discount.Cmp(voucher.MaxDiscount) > 0 {
+			discount = voucher.MaxDiscount
+		}
+	}
+
+	if discount.Cmp(eligible) > 0 {
+		discount = eligible
+	}
+
+	return discount, nil
+}
+
+func toVoucherResponse(voucher *entity.Voucher) dto.VoucherResponse {
+	return dto.VoucherResponse{
+		VoucherID:    strconv.Itoa(voucher.ID),
+		Code:         voucher.Code,
+		DiscountType: voucher.DiscountType,
+		PercentOff:   voucher.PercentOff,
+		AmountOff:    voucher.AmountOff,
+		MaxDiscount:  voucher.MaxDiscount,
+		MinSpend:     voucher.MinSpend,
+		Category:     int(voucher.Category.Int32),
+		UsageLimit:   int(voucher.UsageLimit.Int32),
+		PerUserLimit: int(voucher.PerUserLimit.Int32),
+		UsedCount:    voucher.UsedCount,
+		StartsAt:     voucher.StartsAt.Format(time.RFC3339),
+		EndsAt:       voucher.EndsAt.Format(time.RFC3339),
+		CreatedAt:    voucher.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+)
+
+func idr(amount int64) money.Money {
+	return money.New(amount*100, "IDR")
+}
+
+func TestVoucherDiscount(t *testing.T) {
+	const sellerId = 7
+
+	items := []entity.PurchaseItem{
+		{SellerID: sellerId, Category: 1, Quantity: 2, Price: idr(50000)},
+		{SellerID: sellerId, Category: 2, Quantity: 1, Price: idr(20000)},
+		{SellerID: 8, Category: 1, Quantity: 3, Price: idr(100000)},
+	}
+
+	tests := []struct {
+		name    string
+		voucher entity.Voucher
+		items   []entity.PurchaseItem
+		want    money.Money
+		wantErr error
+	}{
+		{
+			name:    "percentage of the seller items only",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountPercentage, PercentOff: 10},
+			items:   items,
+			want:    idr(12000),
+		},
+		{
+			name:    "percentage capped by the max discount",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountPercentage, PercentOff: 50, MaxDiscount: idr(25000)},
+			items:   items,
+			want:    idr(25000),
+		},
+		{
+			name:    "percentage below the max discount",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountPercentage, PercentOff: 10, MaxDiscount: idr(25000)},
+			items:   items,
+			want:    idr(12000),
+		},
+		{
+			name:    "percentage rounds down to the minor unit",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountPercentage, PercentOff: 15},
+			items:   []entity.PurchaseItem{{SellerID: sellerId, Quantity: 1, Price: money.New(999, "IDR")}},
+			want:    money.New(149, "IDR"),
+		},
+		{
+			name:    "fixed amount",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountFixed, AmountOff: idr(15000)},
+			items:   items,
+			want:    idr(15000),
+		},
+		{
+			name:    "fixed amount above the eligible total",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountFixed, AmountOff: idr(500000)},
+			items:   items,
+			want:    idr(120000),
+		},
+		{
+			name: "category restricted",
+			voucher: entity.Voucher{
+				SellerID:     sellerId,
+				DiscountType: entity.VoucherDiscountPercentage,
+				PercentOff:   10,
+				Category:     sql.NullInt32{Int32: 2, Valid: true},
+			},
+			items: items,
+			want:  idr(2000),
+		},
+		{
+			name:    "min spend met exactly",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountFixed, AmountOff: idr(10000), MinSpend: idr(120000)},
+			items:   items,
+			want:    idr(10000),
+		},
+		{
+			name:    "min spend not met",
+			voucher: entity.Voucher{SellerID: sellerId, DiscountType: entity.VoucherDiscountFixed, AmountOff: idr(10000), MinSpend: idr(120001)},
+			items:   items,
+			wantErr: domain.ErrVoucherMinSpendNotMet,
+		},
+		{
+			name:    "min spend counts eligible items only",
+			voucher: entity.Voucher{SellerID: 8, DiscountType: entity.VoucherDiscountFixed, AmountOff: idr(10000), MinSpend: idr(400000)},
+			items:   items,
+			wantErr: domain.ErrVoucherMinSpendNotMet,
+		},
+		{
+			name:    "no items of the seller",
+			voucher: entity.Voucher{SellerID: 9, DiscountType: entity.VoucherDiscountPercentage, PercentOff: 10},
+			items:   items,
+			wantErr: domain.ErrVoucherNotApplicable,
+		},
+		{
+			name: "no items of the category",
+			voucher: entity.Voucher{
+				SellerID:     sellerId,
+				DiscountType: entity.VoucherDiscountFixed,
+				AmountOff:    idr(10000),
+				Category:     sql.NullInt32{Int32: 3, Valid: true},
+			},
+			items:   items,
+			wantErr: domain.ErrVoucherNotApplicable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := voucherDiscount(&tt.voucher, tt.items)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("voucherDiscount() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Cmp(tt.want) != 0 {
+				t.Errorf("voucherDiscount() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+)
+
+// SQLSTATE codes of the violations translated by MapError
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+// constraintErrors are the domain errors reported when a write violates the
+// named constraint. Constraints missing here fall back to generic errors.
+var constraintErrors = map[string]error{
+	"users_email_key":            domain.ErrUserEmailAlreadyExists,
+	"users_phone_key":            domain.ErrUserPhoneAlreadyExists,
+	"vouchers_code_key":          domain.ErrVoucherCodeAlreadyExists,
+	"fk_file_id":                 domain.ErrFileNotFound,
+	"products_file_id_fkey":      domain.ErrFileNotFound,
+	"cart_items_product_id_fkey": domain.ErrProductNotFound,
+}
+
+// MapError translates unique and foreign key violations into domain errors,
+// so a conflict caught by the database reaches the client as a 4xx instead of
+// a raw Postgres message. Other errors are returned unchanged.
+func MapError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolation:
+		if mapped, ok := constraintErrors[pgErr.ConstraintName]; ok {
+			return mapped
+		}
+
+		return domain.ErrAlreadyExists
+	case foreignKeyViolation:
+		// Deleting a row that is still referenced violates the same
+		// constraint as inserting a reference to a missing row
+		if strings.HasPrefix(pgErr.Message, "update or delete on table") {
+			return domain.ErrStillReferenced
+		}
+
+		if mapped, ok := constraintErrors[pgErr.ConstraintName]; ok {
+			return mapped
+		}
+
+		return domain.ErrReferenceNotFound
+	}
+
+	return err
+}
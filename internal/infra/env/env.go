@@ -8,29 +8,70 @@ import (
 )
 
 type Env struct {
-	AppEnv             string        `mapstructure:"APP_ENV"`
-	AppPort            string        `mapstructure:"APP_PORT"`
-	ApiKey             string        `mapstructure:"API_KEY"`
-	DBHost             string        `mapstructure:"DB_HOST"`
-	DBPort             string        `mapstructure:"DB_PORT"`
-	DBUser             string        `mapstructure:"DB_USER"`
-	DBPass             string        `mapstructure:"DB_PASS"`
-	DBName             string        `mapstructure:"DB_NAME"`
-	JwtSecretKey       string        `mapstructure:"JWT_SECRET_KEY"`
-	JwtExpTime         time.Duration `mapstructure:"JWT_EXP_TIME"`
-	AWSAccessKeyID     string        `mapstructure:"AWS_ACCESS_KEY_ID"`
-	AWSSecretAccessKey string        `mapstructure:"AWS_SECRET_ACCESS_KEY"`
-	AWSS3BucketName    string        `mapstructure:"AWS_S3_BUCKET_NAME"`
-	AWSRegion          string        `mapstructure:"AWS_REGION"`
-	AWSS3Path          string        `mapstructure:"AWS_S3_PATH"`
+	AppEnv                   string        `mapstructure:"APP_ENV"`
+	AppPort                  string        `mapstructure:"APP_PORT"`
+	ProxyHeader              string        `mapstructure:"PROXY_HEADER"`
+	TrustedProxies           []string      `mapstructure:"TRUSTED_PROXIES"`
+	ApiKey                   string        `mapstructure:"API_KEY"`
+	DBHost                   string        `mapstructure:"DB_HOST"`
+	DBPort                   string        `mapstructure:"DB_PORT"`
+	DBUser                   string        `mapstructure:"DB_USER"`
+	DBPass                   string        `mapstructure:"DB_PASS"`
+	DBName                   string        `mapstructure:"DB_NAME"`
+	JwtSecretKey             string        `mapstructure:"JWT_SECRET_KEY"`
+	JwtExpTime               time.Duration `mapstructure:"JWT_EXP_TIME"`
+	RefreshTokenExpTime      time.Duration `mapstructure:"REFRESH_TOKEN_EXP_TIME"`
+	JwtKeysDir               string        `mapstructure:"JWT_KEYS_DIR"`
+	JwtSigningKeyID          string        `mapstructure:"JWT_SIGNING_KEY_ID"`
+	SignatureSecretKey       string        `mapstructure:"SIGNATURE_SECRET_KEY"`
+	AWSAccessKeyID           string        `mapstructure:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey       string        `mapstructure:"AWS_SECRET_ACCESS_KEY"`
+	AWSS3BucketName          string        `mapstructure:"AWS_S3_BUCKET_NAME"`
+	AWSRegion                string        `mapstructure:"AWS_REGION"`
+	AWSS3Path                string        `mapstructure:"AWS_S3_PATH"`
+	AWSS3Endpoint            string        `mapstructure:"AWS_S3_ENDPOINT"`
+	AWSS3UsePathStyle        bool          `mapstructure:"AWS_S3_USE_PATH_STYLE"`
+	AWSS3PublicURL           string        `mapstructure:"AWS_S3_PUBLIC_URL"`
+	FileMaxSize              int64         `mapstructure:"FILE_MAX_SIZE"`
+	Currency                 string        `mapstructure:"CURRENCY"`
+	PurchaseReservationTTL   time.Duration `mapstructure:"PURCHASE_RESERVATION_TTL"`
+	PurchaseReservationSweep time.Duration `mapstructure:"PURCHASE_RESERVATION_SWEEP_INTERVAL"`
+	PurchaseLookupTokenTTL   time.Duration `mapstructure:"PURCHASE_LOOKUP_TOKEN_TTL"`
+	IdempotencyKeyTTL        time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencyKeySweep      time.Duration `mapstructure:"IDEMPOTENCY_KEY_SWEEP_INTERVAL"`
+	ThumbnailMaxSize         int           `mapstructure:"THUMBNAIL_MAX_SIZE"`
+	ThumbnailQuality         int           `mapstructure:"THUMBNAIL_QUALITY"`
+	ThumbnailMaxPixels       int           `mapstructure:"THUMBNAIL_MAX_PIXELS"`
+	ThumbnailWorkers         int           `mapstructure:"THUMBNAIL_WORKERS"`
+	StorageDriver            string        `mapstructure:"STORAGE_DRIVER"`
+	StorageLocalPath         string        `mapstructure:"STORAGE_LOCAL_PATH"`
+	StoragePublicURL         string        `mapstructure:"STORAGE_PUBLIC_URL"`
+	NotifierDriver           string        `mapstructure:"NOTIFIER_DRIVER"`
+	NotifierFilePath         string        `mapstructure:"NOTIFIER_FILE_PATH"`
+	OneTimeCodeTTL           time.Duration `mapstructure:"ONE_TIME_CODE_TTL"`
+	LoginLimiterDriver       string        `mapstructure:"LOGIN_LIMITER_DRIVER"`
+	LoginMaxAttempts         int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginIPMaxAttempts       int           `mapstructure:"LOGIN_IP_MAX_ATTEMPTS"`
+	LoginLockoutBase         time.Duration `mapstructure:"LOGIN_LOCKOUT_BASE"`
+	LoginLockoutMax          time.Duration `mapstructure:"LOGIN_LOCKOUT_MAX"`
+	LoginAttemptWindow       time.Duration `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LoginAttemptSweep        time.Duration `mapstructure:"LOGIN_ATTEMPT_SWEEP_INTERVAL"`
 }
 
-var AppEnv = getEnv()
+// AppEnv holds the configuration once main has called Load. It isn't read at
+// package init, so importing a package doesn't require a config file.
+var AppEnv = &Env{}
+
+// Load reads ./config/.env into AppEnv
+func Load() {
+	AppEnv = getEnv()
+}
 
 func getEnv() *Env {
 	env := &Env{}
 
 	viper.SetConfigFile("./config/.env")
+	setDefaults()
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatal(log.LogInfo{
@@ -55,3 +96,40 @@ func getEnv() *Env {
 
 	return env
 }
+
+func setDefaults() {
+	viper.SetDefault("PROXY_HEADER", "X-Real-IP")
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("JWT_EXP_TIME", "15m")
+	viper.SetDefault("REFRESH_TOKEN_EXP_TIME", "720h")
+	viper.SetDefault("JWT_KEYS_DIR", "")
+	viper.SetDefault("JWT_SIGNING_KEY_ID", "")
+	viper.SetDefault("SIGNATURE_SECRET_KEY", "")
+	viper.SetDefault("FILE_MAX_SIZE", 100*1024)
+	viper.SetDefault("CURRENCY", "IDR")
+	viper.SetDefault("PURCHASE_RESERVATION_TTL", "30m")
+	viper.SetDefault("PURCHASE_RESERVATION_SWEEP_INTERVAL", "1m")
+	viper.SetDefault("PURCHASE_LOOKUP_TOKEN_TTL", "720h")
+	viper.SetDefault("IDEMPOTENCY_KEY_TTL", "24h")
+	viper.SetDefault("IDEMPOTENCY_KEY_SWEEP_INTERVAL", "1h")
+	viper.SetDefault("AWS_S3_ENDPOINT", "")
+	viper.SetDefault("AWS_S3_USE_PATH_STYLE", false)
+	viper.SetDefault("AWS_S3_PUBLIC_URL", "")
+	viper.SetDefault("THUMBNAIL_MAX_SIZE", 200)
+	viper.SetDefault("THUMBNAIL_QUALITY", 75)
+	viper.SetDefault("THUMBNAIL_MAX_PIXELS", 16_000_000)
+	viper.SetDefault("THUMBNAIL_WORKERS", 2)
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_PATH", "./data/uploads")
+	viper.SetDefault("STORAGE_PUBLIC_URL", "http://localhost:8080/uploads")
+	viper.SetDefault("NOTIFIER_DRIVER", "log")
+	viper.SetDefault("NOTIFIER_FILE_PATH", "./data/notifications.log")
+	viper.SetDefault("ONE_TIME_CODE_TTL", "15m")
+	viper.SetDefault("LOGIN_LIMITER_DRIVER", "postgres")
+	viper.SetDefault("LOGIN_MAX_ATTEMPTS", 5)
+	viper.SetDefault("LOGIN_IP_MAX_ATTEMPTS", 20)
+	viper.SetDefault("LOGIN_LOCKOUT_BASE", "30s")
+	viper.SetDefault("LOGIN_LOCKOUT_MAX", "1h")
+	viper.SetDefault("LOGIN_ATTEMPT_WINDOW", "1h")
+	viper.SetDefault("LOGIN_ATTEMPT_SWEEP_INTERVAL", "1h")
+}
@@ -0,0 +1,59 @@
+package limiter
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// Policy decides how long a key is locked out after failing. The first
+// MaxAttempts failures are free, every one after that doubles the lockout,
+// starting at BaseLockout and capped at MaxLockout. Failures are forgotten
+// once none happened for Window.
+type Policy struct {
+	MaxAttempts int
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+	Window      time.Duration
+}
+
+// lockout returns the lockout caused by the nth failure in a row
+func (p Policy) lockout(failures int) time.Duration {
+	if failures <= p.MaxAttempts {
+		return 0
+	}
+
+	lockout := p.BaseLockout
+	for i := p.MaxAttempts + 1; i < failures; i++ {
+		lockout *= 2
+		if lockout >= p.MaxLockout {
+			return p.MaxLockout
+		}
+	}
+
+	return min(lockout, p.MaxLockout)
+}
+
+// NewLoginLimiter builds the limiter of the given driver. Keys only collide
+// within the same scope, so one table or map can back several limiters.
+func NewLoginLimiter(driver string, db *sqlx.DB, scope string, policy Policy) contracts.LoginLimiter {
+	switch driver {
+	case DriverPostgres, "":
+		return NewPostgresLimiter(db, scope, policy)
+	case DriverMemory:
+		return NewMemoryLimiter(policy)
+	}
+
+	log.Fatal(log.LogInfo{
+		"driver": driver,
+	}, "[LIMITER][NewLoginLimiter] unknown login limiter driver")
+
+	return nil
+}
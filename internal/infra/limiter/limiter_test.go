@@ -0,0 +1,51 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyLockout(t *testing.T) {
+	policy := Policy{
+		MaxAttempts: 3,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  5 * time.Minute,
+		Window:      time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		failures int
+		want     time.Duration
+	}{
+		{name: "first failure", policy: policy, failures: 1, want: 0},
+		{name: "last free failure", policy: policy, failures: 3, want: 0},
+		{name: "first locked out failure", policy: policy, failures: 4, want: 30 * time.Second},
+		{name: "doubles", policy: policy, failures: 5, want: time.Minute},
+		{name: "doubles again", policy: policy, failures: 7, want: 4 * time.Minute},
+		{name: "capped", policy: policy, failures: 8, want: 5 * time.Minute},
+		{name: "stays capped", policy: policy, failures: 1000, want: 5 * time.Minute},
+		{
+			name:     "base above the cap",
+			policy:   Policy{MaxAttempts: 1, BaseLockout: time.Hour, MaxLockout: time.Minute},
+			failures: 2,
+			want:     time.Minute,
+		},
+		{
+			name:     "no free failures",
+			policy:   Policy{MaxAttempts: 0, BaseLockout: time.Second, MaxLockout: time.Minute},
+			failures: 1,
+			want:     time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.lockout(tt.failures)
+			if got != tt.want {
+				t.Errorf("lockout(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package limiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+)
+
+// pruneEvery is how many failures are recorded between sweeps of the entries
+// that are neither locked out nor within the window any more
+const pruneEvery = 1024
+
+type attempt struct {
+	failures     int
+	lastFailedAt time.Time
+	lockedUntil  time.Time
+}
+
+type memoryLimiter struct {
+	policy   Policy
+	mu       sync.Mutex
+	attempts map[string]*attempt
+	writes   int
+}
+
+// NewMemoryLimiter keeps the counters in memory. Every replica counts on its
+// own and restarts forget them, so it is meant for development and tests.
+func NewMemoryLimiter(policy Policy) contracts.LoginLimiter {
+	return &memoryLimiter{
+		policy:   policy,
+		attempts: make(map[string]*attempt),
+	}
+}
+
+// Blocked implements contracts.LoginLimiter.
+func (l *memoryLimiter) Blocked(_ context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	a, ok := l.attempts[key]
+	if !ok {
+		return 0, nil
+	}
+
+	return max(time.Until(a.lockedUntil), 0), nil
+}
+
+// Fail implements contracts.LoginLimiter.
+func (l *memoryLimiter) Fail(_ context.Context, key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.writes++
+	if l.writes%pruneEvery == 0 {
+		l.prune(now)
+	}
+
+	a, ok := l.attempts[key]
+	if !ok || now.Sub(a.lastFailedAt) > l.policy.Window {
+		a = &attempt{}
+		l.attempts[key] = a
+	}
+
+	a.failures++
+	a.lastFailedAt = now
+
+	lockout := l.policy.lockout(a.failures)
+	if lockout > 0 {
+		a.lockedUntil = now.Add(lockout)
+	}
+
+	return lockout, nil
+}
+
+// Reset implements contracts.LoginLimiter.
+func (l *memoryLimiter) Reset(_ context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.attempts, key)
+	return nil
+}
+
+// Prune implements contracts.LoginLimiter.
+func (l *memoryLimiter) Prune(_ context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(time.Now())
+	return nil
+}
+
+func (l *memoryLimiter) prune(now time.Time) {
+	for key, a := range l.attempts {
+		if now.Sub(a.lastFailedAt) > l.policy.Window && now.After(a.lockedUntil) {
+			delete(l.attempts, key)
+		}
+	}
+}
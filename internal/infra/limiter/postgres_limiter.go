@@ -0,0 +1,98 @@
+package limiter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+)
+
+type postgresLimiter struct {
+	db     *sqlx.DB
+	scope  string
+	policy Policy
+}
+
+// NewPostgresLimiter keeps the counters in the "login_attempts" table, so
+// they are shared by every replica of the app
+func NewPostgresLimiter(db *sqlx.DB, scope string, policy Policy) contracts.LoginLimiter {
+	return &postgresLimiter{db, scope, policy}
+}
+
+// Blocked implements contracts.LoginLimiter.
+func (l *postgresLimiter) Blocked(ctx context.Context, key string) (time.Duration, error) {
+	var seconds float64
+	err := l.db.QueryRowxContext(ctx, `
+		SELECT EXTRACT(EPOCH FROM locked_until - CURRENT_TIMESTAMP)
+		FROM login_attempts
+		WHERE scope = $1 AND key = $2 AND locked_until > CURRENT_TIMESTAMP
+	`, l.scope, key).Scan(&seconds)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Fail implements contracts.LoginLimiter. It runs in its own transaction
+// rather than the caller's, since the failure has to be counted even though
+// the login is rejected.
+func (l *postgresLimiter) Fail(ctx context.Context, key string) (time.Duration, error) {
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var failures int
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO login_attempts (scope, key, failures)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET failures = CASE
+				WHEN login_attempts.last_failed_at < CURRENT_TIMESTAMP - $3 * INTERVAL '1 second' THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failed_at = CURRENT_TIMESTAMP
+		RETURNING failures
+	`, l.scope, key, l.policy.Window.Seconds()).Scan(&failures)
+	if err != nil {
+		return 0, err
+	}
+
+	lockout := l.policy.lockout(failures)
+	if lockout > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE login_attempts SET locked_until = CURRENT_TIMESTAMP + $1 * INTERVAL '1 second' WHERE scope = $2 AND key = $3",
+			lockout.Seconds(), l.scope, key,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return lockout, tx.Commit()
+}
+
+// Reset implements contracts.LoginLimiter.
+func (l *postgresLimiter) Reset(ctx context.Context, key string) error {
+	_, err := l.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE scope = $1 AND key = $2", l.scope, key)
+	return err
+}
+
+// Prune implements contracts.LoginLimiter.
+func (l *postgresLimiter) Prune(ctx context.Context) error {
+	_, err := l.db.ExecContext(ctx, `
+		DELETE FROM login_attempts
+		WHERE scope = $1 AND last_failed_at < CURRENT_TIMESTAMP - $2 * INTERVAL '1 second'
+		AND (locked_until IS NULL OR locked_until <= CURRENT_TIMESTAMP)
+	`, l.scope, l.policy.Window.Seconds())
+	return err
+}
@@ -0,0 +1,38 @@
+package limiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+// StartSweeper periodically prunes the limiters, so keys that failed once and
+// never came back don't pile up. It is safe to run on every replica.
+func StartSweeper(ctx context.Context, interval time.Duration, limiters ...contracts.LoginLimiter) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, limiter := range limiters {
+					err := limiter.Prune(ctx)
+					if err != nil {
+						log.Error(log.LogInfo{
+							"error": err.Error(),
+						}, "[SCHEDULER][LimiterSweeper] failed to prune login attempts")
+					}
+				}
+			}
+		}
+	}()
+}
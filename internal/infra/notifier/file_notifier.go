@@ -0,0 +1,51 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+)
+
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier appends every message to the file at path as a line of
+// JSON, for tests and local setups to read the codes back from
+func NewFileNotifier(path string) contracts.Notifier {
+	return &fileNotifier{path: path}
+}
+
+// Send implements contracts.Notifier.
+func (n *fileNotifier) Send(_ context.Context, notification *dto.Notification) error {
+	line, err := json.Marshal(struct {
+		*dto.Notification
+		SentAt time.Time `json:"sentAt"`
+	}{notification, time.Now()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(n.path), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
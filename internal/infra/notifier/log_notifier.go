@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"context"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/dto"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+type logNotifier struct{}
+
+// NewLogNotifier writes messages to the application log instead of sending them
+func NewLogNotifier() contracts.Notifier {
+	return &logNotifier{}
+}
+
+// Send implements contracts.Notifier.
+func (n *logNotifier) Send(_ context.Context, notification *dto.Notification) error {
+	log.Info(log.LogInfo{
+		"channel": notification.Channel,
+		"to":      notification.To,
+		"subject": notification.Subject,
+		"body":    notification.Body,
+	}, "[NOTIFIER][Send] notification")
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/env"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+// NewNotifier builds the notifier selected by NOTIFIER_DRIVER. Both drivers
+// keep messages local, so flows that send codes can be run offline.
+func NewNotifier() contracts.Notifier {
+	switch env.AppEnv.NotifierDriver {
+	case DriverLog, "":
+		return NewLogNotifier()
+	case DriverFile:
+		return NewFileNotifier(env.AppEnv.NotifierFilePath)
+	}
+
+	log.Fatal(log.LogInfo{
+		"driver": env.AppEnv.NotifierDriver,
+	}, "[NOTIFIER][NewNotifier] unknown notifier driver")
+
+	return nil
+}
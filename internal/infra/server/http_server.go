@@ -1,19 +1,51 @@
 package server
 
 import (
+	"context"
+
 	"github.com/bytedance/sonic"
 	"github.com/gofiber/fiber/v2"
 	"github.com/jmoiron/sqlx"
 	authController "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/auth/controller"
 	authRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/auth/repository"
 	authSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/auth/service"
+	cartController "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/cart/controller"
+	cartRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/cart/repository"
+	cartSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/cart/service"
+	fileController "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/file/controller"
+	fileRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/file/repository"
+	fileSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/file/service"
+	idempotencyRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/idempotency/repository"
+	idempotencyScheduler "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/idempotency/scheduler"
+	otpRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/otp/repository"
+	otpSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/otp/service"
+	productController "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/product/controller"
+	productRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/product/repository"
+	productSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/product/service"
+	purchaseController "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/purchase/controller"
+	purchaseRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/purchase/repository"
+	purchaseScheduler "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/purchase/scheduler"
+	purchaseSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/purchase/service"
+	sessionRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/session/repository"
 	userController "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/user/controller"
 	userRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/user/repository"
 	userSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/user/service"
+	voucherController "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/voucher/controller"
+	voucherRepo "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/voucher/repository"
+	voucherSvc "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/app/voucher/service"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/database"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/env"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/limiter"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/notifier"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/storage"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/middlewares"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/bcrypt"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/helpers/http/errorhandler"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/helpers/http/response"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/signature"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/thumbnail"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
 
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
@@ -37,7 +69,13 @@ func NewHttpServer() HttpServer {
 		ServerHeader:  "Tutuplapak",
 		JSONEncoder:   sonic.Marshal,
 		JSONDecoder:   sonic.Unmarshal,
-		//ErrorHandler:  errorhandler.ErrorHandler,
+		ErrorHandler:  errorhandler.ErrorHandler,
+		// Requests reach the app through nginx, the client IP is taken from
+		// the header it sets, but only when the request comes from it
+		ProxyHeader:             env.AppEnv.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          env.AppEnv.TrustedProxies,
+		EnableIPValidation:      true,
 	}
 	app := fiber.New(config)
 	return &httpServer{
@@ -67,25 +105,96 @@ func (s httpServer) MountMiddlewares() {
 }
 
 func (s httpServer) MountRoutes(db *sqlx.DB) {
+	money.SetDefaultCurrency(env.AppEnv.Currency)
+
 	validator := validator.Validator
-	jwt := jwt.Jwt
 	bcrypt := bcrypt.Bcrypt
-	middleware := middlewares.NewMiddleware(jwt)
+	thumbnail := thumbnail.New(env.AppEnv.ThumbnailMaxSize, env.AppEnv.ThumbnailQuality, env.AppEnv.ThumbnailMaxPixels, env.AppEnv.ThumbnailWorkers)
+	jwt, err := jwt.New(jwt.Config{
+		SecretKey:    env.AppEnv.JwtSecretKey,
+		ExpiredTime:  env.AppEnv.JwtExpTime,
+		KeysDir:      env.AppEnv.JwtKeysDir,
+		SigningKeyID: env.AppEnv.JwtSigningKeyID,
+	})
+	if err != nil {
+		log.Fatal(log.LogInfo{
+			"error": err.Error(),
+			"kid":   env.AppEnv.JwtSigningKeyID,
+		}, "[SERVER][MountRoutes] failed to load jwt keys")
+	}
+
+	signatureSecretKey := env.AppEnv.SignatureSecretKey
+	if signatureSecretKey == "" {
+		signatureSecretKey = env.AppEnv.JwtSecretKey
+	}
+	signature, err := signature.New(signatureSecretKey)
+	if err != nil {
+		log.Fatal(log.LogInfo{
+			"error": err.Error(),
+		}, "[SERVER][MountRoutes] set SIGNATURE_SECRET_KEY or JWT_SECRET_KEY")
+	}
+	sessionRepository := sessionRepo.NewSessionRepository(db)
+	idempotencyRepository := idempotencyRepo.NewIdempotencyRepository(db)
+	middleware := middlewares.NewMiddleware(jwt, sessionRepository, idempotencyRepository, env.AppEnv.IdempotencyKeyTTL)
 
 	s.app.Get("/", func(c *fiber.Ctx) error {
 		return response.SendResponse(c, fiber.StatusOK, "Welcome to Tutuplapak API")
 	})
 
+	s.app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.Status(fiber.StatusOK).JSON(jwt.JWKS())
+	})
+
+	if env.AppEnv.StorageDriver == storage.DriverLocal {
+		s.app.Static("/uploads", env.AppEnv.StorageLocalPath)
+	}
+
 	api := s.app.Group("/v1")
 
+	fileStorage := storage.NewFileStorage()
+	notifier := notifier.NewNotifier()
+
 	authRepository := authRepo.NewAuthRepository(db)
 	userRepository := userRepo.NewUserRepository(db)
-
-	authService := authSvc.NewAuthService(authRepository, validator, bcrypt, jwt)
-	userService := userSvc.NewUserService(userRepository, validator)
-
-	authController.InitAuthController(api, authService)
+	productRepository := productRepo.NewProductRepository(db)
+	fileRepository := fileRepo.NewFileRepository(db)
+	purchaseRepository := purchaseRepo.NewPurchaseRepository(db)
+	voucherRepository := voucherRepo.NewVoucherRepository(db)
+	cartRepository := cartRepo.NewCartRepository(db)
+	oneTimeCodeRepository := otpRepo.NewOneTimeCodeRepository(db)
+	unitOfWork := database.NewUnitOfWork(db)
+	loginPolicy := limiter.Policy{
+		MaxAttempts: env.AppEnv.LoginMaxAttempts,
+		BaseLockout: env.AppEnv.LoginLockoutBase,
+		MaxLockout:  env.AppEnv.LoginLockoutMax,
+		Window:      env.AppEnv.LoginAttemptWindow,
+	}
+	identifierLimiter := limiter.NewLoginLimiter(env.AppEnv.LoginLimiterDriver, db, "identifier", loginPolicy)
+	loginPolicy.MaxAttempts = env.AppEnv.LoginIPMaxAttempts
+	ipLimiter := limiter.NewLoginLimiter(env.AppEnv.LoginLimiterDriver, db, "ip", loginPolicy)
+
+	otpService := otpSvc.NewOneTimeCodeService(oneTimeCodeRepository, unitOfWork, notifier, bcrypt, env.AppEnv.OneTimeCodeTTL)
+	authService := authSvc.NewAuthService(authRepository, sessionRepository, otpService, identifierLimiter, ipLimiter, unitOfWork, validator, bcrypt, jwt, env.AppEnv.RefreshTokenExpTime)
+	fileService := fileSvc.NewFileService(fileRepository, fileStorage, thumbnail, signature, env.AppEnv.FileMaxSize)
+	userService := userSvc.NewUserService(userRepository, sessionRepository, otpService, fileService, identifierLimiter, unitOfWork, validator, bcrypt)
+	productService := productSvc.NewProductService(productRepository, fileService, validator)
+	voucherService := voucherSvc.NewVoucherService(voucherRepository, validator)
+	purchaseService := purchaseSvc.NewPurchaseService(purchaseRepository, unitOfWork, fileService, voucherService, validator, signature, env.AppEnv.PurchaseReservationTTL, env.AppEnv.PurchaseLookupTokenTTL)
+	cartService := cartSvc.NewCartService(cartRepository, unitOfWork, purchaseService, validator, signature)
+
+	authController.InitAuthController(api, authService, middleware)
 	userController.InitUserController(api, userService, middleware)
+	productController.InitProductController(api, productService, middleware)
+	fileController.InitFileController(api, fileService, middleware)
+	purchaseController.InitPurchaseController(api, purchaseService, middleware)
+	purchaseController.InitSellerController(api, purchaseService, middleware)
+	voucherController.InitVoucherController(api, voucherService, middleware)
+	cartController.InitCartController(api, cartService, middleware)
+
+	purchaseScheduler.StartReservationSweeper(context.Background(), purchaseService, env.AppEnv.PurchaseReservationSweep)
+	limiter.StartSweeper(context.Background(), env.AppEnv.LoginAttemptSweep, identifierLimiter, ipLimiter)
+	idempotencyScheduler.StartKeySweeper(context.Background(), idempotencyRepository, env.AppEnv.IdempotencyKeyTTL, env.AppEnv.IdempotencyKeySweep)
 
 	api.Get("/", func(c *fiber.Ctx) error {
 		return response.SendResponse(c, fiber.StatusOK, "TutupLapak API v1")
@@ -94,14 +203,6 @@ func (s httpServer) MountRoutes(db *sqlx.DB) {
 	s.app.Use(func(c *fiber.Ctx) error {
 		return c.SendFile("./web/not-found.html")
 	})
-
-	// // Initialize repositories
-	// purchaseRepository := purchaseRepo.NewPurchaseRepository(db)
-
-	// // Initialize services
-	// purchaseService := purchaseSvc.NewPurchaseService(purchaseRepository, validator)
-
-	// purchaseCtr.InitPurchaseController(api, purchaseService)
 }
 
 func (s httpServer) GetApp() *fiber.App {
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+type localStorage struct {
+	basePath  string
+	publicURL string
+}
+
+// NewLocalStorage stores files on the local disk under basePath. The files are
+// expected to be served from publicURL.
+func NewLocalStorage(basePath, publicURL string) contracts.FileStorage {
+	return &localStorage{
+		basePath:  basePath,
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// Put implements contracts.FileStorage.
+func (s *localStorage) Put(_ context.Context, key string, _ string, body io.Reader, _ int64) (string, error) {
+	fullPath, err := s.resolve(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(fullPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, body); err != nil {
+		os.Remove(fullPath)
+		return "", err
+	}
+
+	return s.publicURL + "/" + key, nil
+}
+
+// Delete implements contracts.FileStorage.
+func (s *localStorage) Delete(_ context.Context, key string) error {
+	fullPath, err := s.resolve(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(fullPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// resolve maps a storage key to a path inside basePath
+func (s *localStorage) resolve(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.basePath, filepath.FromSlash(key)), nil
+}
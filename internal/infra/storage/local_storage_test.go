@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoragePutAndDelete(t *testing.T) {
+	ctx := context.Background()
+	basePath := t.TempDir()
+	storage := NewLocalStorage(basePath, "http://localhost:8080/uploads/")
+
+	uri, err := storage.Put(ctx, "files/1/photo.jpg", "image/jpeg", strings.NewReader("content"), 7)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if want := "http://localhost:8080/uploads/files/1/photo.jpg"; uri != want {
+		t.Errorf("Put() = %q, want %q", uri, want)
+	}
+
+	fullPath := filepath.Join(basePath, "files", "1", "photo.jpg")
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		t.Fatalf("failed to read stored file: %v", err)
+	}
+
+	if string(content) != "content" {
+		t.Errorf("stored content = %q, want %q", content, "content")
+	}
+
+	if err := storage.Delete(ctx, "files/1/photo.jpg"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err := os.Stat(fullPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file still exists after Delete(), stat error = %v", err)
+	}
+
+	if err := storage.Delete(ctx, "files/1/photo.jpg"); err != nil {
+		t.Errorf("Delete() of a missing file error = %v, want nil", err)
+	}
+}
+
+func TestLocalStorageResolve(t *testing.T) {
+	basePath := t.TempDir()
+	storage := &localStorage{basePath: basePath}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr error
+	}{
+		{name: "file", key: "photo.jpg", want: filepath.Join(basePath, "photo.jpg")},
+		{name: "nested file", key: "a/b/photo.jpg", want: filepath.Join(basePath, "a", "b", "photo.jpg")},
+		{name: "empty", key: "", wantErr: ErrInvalidKey},
+		{name: "parent directory", key: "../photo.jpg", wantErr: ErrInvalidKey},
+		{name: "parent directory in the middle", key: "a/../../photo.jpg", wantErr: ErrInvalidKey},
+		{name: "absolute", key: "/etc/passwd", wantErr: ErrInvalidKey},
+		{name: "current directory", key: "./photo.jpg", wantErr: ErrInvalidKey},
+		{name: "double slash", key: "a//photo.jpg", wantErr: ErrInvalidKey},
+		{name: "trailing slash", key: "a/", wantErr: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := storage.resolve(tt.key)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("resolve(%q) error = %v, want %v", tt.key, err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("resolve(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalStoragePutRejectsInvalidKey(t *testing.T) {
+	basePath := t.TempDir()
+	storage := NewLocalStorage(filepath.Join(basePath, "uploads"), "http://localhost:8080/uploads")
+
+	_, err := storage.Put(context.Background(), "../escaped.txt", "text/plain", strings.NewReader("content"), 7)
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("Put() error = %v, want %v", err, ErrInvalidKey)
+	}
+
+	if _, err := os.Stat(filepath.Join(basePath, "escaped.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file was written outside the base path, stat error = %v", err)
+	}
+}
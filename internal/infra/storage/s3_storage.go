@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+)
+
+type S3Config struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Region          string
+	Bucket          string
+	// Prefix is prepended to every object key
+	Prefix string
+	// Endpoint overrides the AWS endpoint, e.g. to point at MinIO
+	Endpoint     string
+	UsePathStyle bool
+	// PublicURL is the base URL objects are served from. When empty it is
+	// derived from the endpoint or the bucket and region.
+	PublicURL string
+}
+
+type s3Storage struct {
+	client    *s3.Client
+	bucket    string
+	prefix    string
+	publicURL string
+}
+
+// NewS3Storage stores files in an S3 bucket or any S3-compatible object store.
+// With an endpoint set the bucket is created when it doesn't exist yet, a
+// fresh MinIO starts out without any.
+func NewS3Storage(ctx context.Context, cfg S3Config) (contracts.FileStorage, error) {
+	opts := []func(*config.LoadOptions) error{
+		config.WithRegion(cfg.Region),
+	}
+
+	if cfg.AccessKeyID != "" {
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AccessKeyID, cfg.SecretAccessKey, ""),
+		))
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+			// S3-compatible stores don't always support the newer default checksums
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		}
+		o.UsePathStyle = cfg.UsePathStyle
+	})
+
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		publicURL = defaultS3PublicURL(cfg)
+	}
+
+	storage := &s3Storage{
+		client:    client,
+		bucket:    cfg.Bucket,
+		prefix:    strings.Trim(cfg.Prefix, "/"),
+		publicURL: strings.TrimRight(publicURL, "/"),
+	}
+
+	if cfg.Endpoint != "" {
+		if err := storage.ensureBucket(ctx, cfg.Region); err != nil {
+			return nil, err
+		}
+	}
+
+	return storage, nil
+}
+
+func (s *s3Storage) ensureBucket(ctx context.Context, region string) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(s.bucket),
+	})
+	if err == nil {
+		return nil
+	}
+
+	var notFound *types.NotFound
+	if !errors.As(err, &notFound) {
+		return err
+	}
+
+	input := &s3.CreateBucketInput{
+		Bucket: aws.String(s.bucket),
+	}
+	if region != "" && region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(region),
+		}
+	}
+
+	// Another replica may have created it in the meantime
+	var ownedByYou *types.BucketAlreadyOwnedByYou
+	_, err = s.client.CreateBucket(ctx, input)
+	if err != nil && !errors.As(err, &ownedByYou) {
+		return err
+	}
+
+	return nil
+}
+
+// Put implements contracts.FileStorage.
+func (s *s3Storage) Put(ctx context.Context, key string, contentType string, body io.Reader, size int64) (string, error) {
+	objectKey := s.objectKey(key)
+
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(objectKey),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return s.publicURL + "/" + objectKey, nil
+}
+
+// Delete implements contracts.FileStorage.
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.objectKey(key)),
+	})
+
+	return err
+}
+
+func (s *s3Storage) objectKey(key string) string {
+	if s.prefix == "" {
+		return key
+	}
+
+	return path.Join(s.prefix, key)
+}
+
+func defaultS3PublicURL(cfg S3Config) string {
+	if cfg.Endpoint == "" {
+		return "https://" + cfg.Bucket + ".s3." + cfg.Region + ".amazonaws.com"
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || cfg.UsePathStyle {
+		return strings.TrimRight(cfg.Endpoint, "/") + "/" + cfg.Bucket
+	}
+
+	endpoint.Host = cfg.Bucket + "." + endpoint.Host
+	return endpoint.String()
+}
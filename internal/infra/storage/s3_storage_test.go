@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeObject struct {
+	contentType string
+	body        []byte
+}
+
+// fakeS3 is an in-process S3 that understands the path-style requests the
+// storage sends. It doesn't check signatures.
+type fakeS3 struct {
+	mu             sync.Mutex
+	buckets        map[string]map[string]fakeObject
+	createRequests []string
+}
+
+func newFakeS3(t *testing.T, buckets ...string) (*fakeS3, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeS3{buckets: map[string]map[string]fakeObject{}}
+	for _, bucket := range buckets {
+		fake.buckets[bucket] = map[string]fakeObject{}
+	}
+
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, exists := f.buckets[bucket]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case key == "" && r.Method == http.MethodPut:
+		f.createRequests = append(f.createRequests, string(body))
+		if exists {
+			writeS3Error(w, http.StatusConflict, "BucketAlreadyOwnedByYou")
+			return
+		}
+		f.buckets[bucket] = map[string]fakeObject{}
+		w.WriteHeader(http.StatusOK)
+
+	case !exists:
+		writeS3Error(w, http.StatusNotFound, "NoSuchBucket")
+
+	case r.Method == http.MethodPut:
+		objects[key] = fakeObject{contentType: r.Header.Get("Content-Type"), body: body}
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>` + code + `</Code><Message>` + code + `</Message></Error>`))
+}
+
+func (f *fakeS3) object(bucket, key string) (fakeObject, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	object, ok := f.buckets[bucket][key]
+	return object, ok
+}
+
+func newTestS3Storage(t *testing.T, endpoint string, region string) *s3Storage {
+	t.Helper()
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	storage, err := NewS3Storage(context.Background(), S3Config{
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		Region:          region,
+		Bucket:          "uploads",
+		Prefix:          "/tutuplapak/",
+		Endpoint:        endpoint,
+		UsePathStyle:    true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+
+	return storage.(*s3Storage)
+}
+
+func TestS3StoragePutAndDelete(t *testing.T) {
+	ctx := context.Background()
+	fake, server := newFakeS3(t, "uploads")
+	storage := newTestS3Storage(t, server.URL, "us-east-1")
+
+	content := []byte("content")
+	uri, err := storage.Put(ctx, "files/photo.jpg", "image/jpeg", bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if want := server.URL + "/uploads/tutuplapak/files/photo.jpg"; uri != want {
+		t.Errorf("Put() = %q, want %q", uri, want)
+	}
+
+	object, ok := fake.object("uploads", "tutuplapak/files/photo.jpg")
+	if !ok {
+		t.Fatalf("object wasn't stored under the prefix")
+	}
+
+	if !bytes.Equal(object.body, content) || object.contentType != "image/jpeg" {
+		t.Errorf("stored object = %q %s, want %q image/jpeg", object.body, object.contentType, content)
+	}
+
+	if err := storage.Delete(ctx, "files/photo.jpg"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, ok := fake.object("uploads", "tutuplapak/files/photo.jpg"); ok {
+		t.Errorf("object still exists after Delete()")
+	}
+}
+
+func TestNewS3StorageCreatesMissingBucket(t *testing.T) {
+	tests := []struct {
+		name        string
+		buckets     []string
+		region      string
+		wantCreates int
+		wantBody    string
+	}{
+		{name: "existing bucket", buckets: []string{"uploads"}, region: "us-east-1"},
+		{name: "missing bucket", region: "us-east-1", wantCreates: 1},
+		{name: "missing bucket outside us-east-1", region: "ap-southeast-3", wantCreates: 1, wantBody: "<LocationConstraint>ap-southeast-3</LocationConstraint>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, server := newFakeS3(t, tt.buckets...)
+			newTestS3Storage(t, server.URL, tt.region)
+
+			if len(fake.createRequests) != tt.wantCreates {
+				t.Fatalf("bucket created %d times, want %d", len(fake.createRequests), tt.wantCreates)
+			}
+
+			if tt.wantCreates > 0 && !strings.Contains(fake.createRequests[0], tt.wantBody) {
+				t.Errorf("create bucket request = %q, want it to contain %q", fake.createRequests[0], tt.wantBody)
+			}
+
+			if _, ok := fake.buckets["uploads"]; !ok {
+				t.Errorf("bucket doesn't exist")
+			}
+		})
+	}
+}
+
+func TestNewS3StorageFailsWhenBucketIsUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+
+	_, err := NewS3Storage(context.Background(), S3Config{
+		AccessKeyID:     "access",
+		SecretAccessKey: "secret",
+		Region:          "us-east-1",
+		Bucket:          "uploads",
+		Endpoint:        server.URL,
+		UsePathStyle:    true,
+	})
+	if err == nil {
+		t.Fatalf("NewS3Storage() error = nil, want the error of the forbidden bucket")
+	}
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/env"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// NewFileStorage builds the storage backend selected by STORAGE_DRIVER
+func NewFileStorage() contracts.FileStorage {
+	switch env.AppEnv.StorageDriver {
+	case DriverLocal, "":
+		return NewLocalStorage(env.AppEnv.StorageLocalPath, env.AppEnv.StoragePublicURL)
+	case DriverS3:
+		storage, err := NewS3Storage(context.Background(), S3Config{
+			AccessKeyID:     env.AppEnv.AWSAccessKeyID,
+			SecretAccessKey: env.AppEnv.AWSSecretAccessKey,
+			Region:          env.AppEnv.AWSRegion,
+			Bucket:          env.AppEnv.AWSS3BucketName,
+			Prefix:          env.AppEnv.AWSS3Path,
+			Endpoint:        env.AppEnv.AWSS3Endpoint,
+			UsePathStyle:    env.AppEnv.AWSS3UsePathStyle,
+			PublicURL:       env.AppEnv.AWSS3PublicURL,
+		})
+		if err != nil {
+			log.Fatal(log.LogInfo{
+				"error": err.Error(),
+			}, "[STORAGE][NewFileStorage] failed to create s3 storage")
+		}
+
+		return storage
+	}
+
+	log.Fatal(log.LogInfo{
+		"driver": env.AppEnv.StorageDriver,
+	}, "[STORAGE][NewFileStorage] unknown storage driver")
+
+	return nil
+}
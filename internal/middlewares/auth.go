@@ -16,34 +16,29 @@ func (m *Middleware) RequireAuth() fiber.Handler {
 			return domain.ErrNoBearerToken
 		}
 
-		headerSlice := strings.Split(header, " ")
-		if len(headerSlice) != 2 && headerSlice[0] != "Bearer" {
-			return domain.ErrInvalidBearerToken
-		}
-
-		token := headerSlice[1]
-		var claims jwt.Claims
-		err := m.jwt.Decode(token, &claims)
+		claims, err := m.decodeBearerToken(ctx, header)
 		if err != nil {
-			return domain.ErrInvalidBearerToken
+			return err
 		}
 
-		notBefore, err := claims.GetNotBefore()
-		if err != nil {
-			return domain.ErrInvalidBearerToken
-		}
+		ctx.Locals("claims", claims)
 
-		if notBefore.After(time.Now()) {
-			return domain.ErrBearerTokenNotActive
-		}
+		return ctx.Next()
+	}
+}
 
-		expirationTime, err := claims.GetExpirationTime()
-		if err != nil {
-			return domain.ErrInvalidBearerToken
+// OptionalAuth sets the claims when a bearer token is sent and lets anonymous
+// requests through. An invalid token is still rejected.
+func (m *Middleware) OptionalAuth() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		header := ctx.Get("Authorization")
+		if header == "" {
+			return ctx.Next()
 		}
 
-		if expirationTime.Before(time.Now()) {
-			return domain.ErrExpiredBearerToken
+		claims, err := m.decodeBearerToken(ctx, header)
+		if err != nil {
+			return err
 		}
 
 		ctx.Locals("claims", claims)
@@ -51,3 +46,47 @@ func (m *Middleware) RequireAuth() fiber.Handler {
 		return ctx.Next()
 	}
 }
+
+// decodeBearerToken checks the token and that its session hasn't been revoked
+func (m *Middleware) decodeBearerToken(ctx *fiber.Ctx, header string) (jwt.Claims, error) {
+	headerSlice := strings.Split(header, " ")
+	if len(headerSlice) != 2 || headerSlice[0] != "Bearer" {
+		return jwt.Claims{}, domain.ErrInvalidBearerToken
+	}
+
+	token := headerSlice[1]
+	var claims jwt.Claims
+	err := m.jwt.Decode(token, &claims)
+	if err != nil {
+		return jwt.Claims{}, domain.ErrInvalidBearerToken
+	}
+
+	notBefore, err := claims.GetNotBefore()
+	if err != nil {
+		return jwt.Claims{}, domain.ErrInvalidBearerToken
+	}
+
+	if notBefore.After(time.Now()) {
+		return jwt.Claims{}, domain.ErrBearerTokenNotActive
+	}
+
+	expirationTime, err := claims.GetExpirationTime()
+	if err != nil {
+		return jwt.Claims{}, domain.ErrInvalidBearerToken
+	}
+
+	if expirationTime.Before(time.Now()) {
+		return jwt.Claims{}, domain.ErrExpiredBearerToken
+	}
+
+	active, err := m.sessionRepository.IsActive(ctx.Context(), claims.ID)
+	if err != nil {
+		return jwt.Claims{}, fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
+	if !active {
+		return jwt.Claims{}, domain.ErrRevokedBearerToken
+	}
+
+	return claims, nil
+}
@@ -8,7 +8,7 @@ import (
 func Cors() fiber.Handler {
 	config := cors.Config{
 		AllowMethods:  "GET,POST,PUT,DELETE,PATCH,OPTIONS,HEAD",
-		AllowHeaders:  "Content-Type,Authorization,X-API-Key,Accept,Origin,X-Requested-With,X-XSRF-Token,X-Cursor,Token-Type",
+		AllowHeaders:  "Content-Type,Authorization,X-API-Key,Accept,Origin,X-Requested-With,X-XSRF-Token,X-Cursor,Token-Type,Idempotency-Key,X-Cart-Token,X-Lookup-Token",
 		ExposeHeaders: "Content-Length",
 	}
 
@@ -0,0 +1,152 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// Idempotency makes a route safe to retry. The first request with an
+// Idempotency-Key header runs as usual and its response is stored; retries
+// with the same key and body get the stored response back without running
+// the handler again. Requests without the header are not affected.
+//
+// It has to run after the auth middleware, keys are scoped to the user.
+func (m *Middleware) Idempotency() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		key := ctx.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return ctx.Next()
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			return domain.ErrInvalidIdempotencyKey
+		}
+
+		idempotencyKey := &entity.IdempotencyKey{
+			Key:         key,
+			Scope:       idempotencyScope(ctx),
+			RequestHash: requestFingerprint(ctx),
+		}
+
+		claimed, err := m.idempotencyRepository.Claim(ctx.Context(), idempotencyKey, m.idempotencyKeyTTL)
+		if err != nil {
+			return err
+		}
+
+		if !claimed {
+			return m.replay(ctx, idempotencyKey)
+		}
+
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				m.release(ctx, idempotencyKey)
+				return err
+			}
+		}
+
+		// Server errors aren't stored, so the request can be retried
+		statusCode := ctx.Response().StatusCode()
+		if statusCode >= fiber.StatusInternalServerError {
+			m.release(ctx, idempotencyKey)
+			return nil
+		}
+
+		idempotencyKey.StatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+		idempotencyKey.ContentType = string(ctx.Response().Header.ContentType())
+		idempotencyKey.ResponseBody = append([]byte(nil), ctx.Response().Body()...)
+
+		err = m.idempotencyRepository.Complete(ctx.Context(), idempotencyKey)
+		if err != nil {
+			// Keep the claim, running the request again could duplicate it
+			log.Error(log.LogInfo{
+				"error": err.Error(),
+				"key":   idempotencyKey.Key,
+				"scope": idempotencyKey.Scope,
+			}, "[IdempotencyMiddleware] failed to store response")
+		}
+
+		return nil
+	}
+}
+
+func (m *Middleware) replay(ctx *fiber.Ctx, idempotencyKey *entity.IdempotencyKey) error {
+	stored, err := m.idempotencyRepository.FindByKey(ctx.Context(), idempotencyKey.Key, idempotencyKey.Scope)
+	if err != nil {
+		// The first request failed and released the key in the meantime
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrIdempotencyKeyInProgress
+		}
+
+		return err
+	}
+
+	if stored.RequestHash != idempotencyKey.RequestHash {
+		return domain.ErrIdempotencyKeyReused
+	}
+
+	if !stored.CompletedAt.Valid {
+		return domain.ErrIdempotencyKeyInProgress
+	}
+
+	ctx.Set(IdempotencyReplayedHeader, "true")
+	if stored.ContentType != "" {
+		ctx.Set(fiber.HeaderContentType, stored.ContentType)
+	}
+
+	return ctx.Status(int(stored.StatusCode.Int32)).Send(stored.ResponseBody)
+}
+
+func (m *Middleware) release(ctx *fiber.Ctx, idempotencyKey *entity.IdempotencyKey) {
+	err := m.idempotencyRepository.Release(ctx.Context(), idempotencyKey.Key, idempotencyKey.Scope)
+	if err != nil {
+		log.Error(log.LogInfo{
+			"error": err.Error(),
+			"key":   idempotencyKey.Key,
+			"scope": idempotencyKey.Scope,
+		}, "[IdempotencyMiddleware] failed to release key")
+	}
+}
+
+// idempotencyScope keeps keys of different routes and users apart. Guests are
+// told apart by their IP address and the lookup token they send, so one guest
+// can't replay the response stored for another.
+func idempotencyScope(ctx *fiber.Ctx) string {
+	user := "guest:" + ctx.IP()
+	if token := ctx.Get(LookupTokenHeader); token != "" {
+		hash := sha256.Sum256([]byte(token))
+		user += ":" + hex.EncodeToString(hash[:8])
+	}
+
+	if claims, ok := ctx.Locals("claims").(jwt.Claims); ok {
+		user = fmt.Sprintf("user:%d", claims.UserID)
+	}
+
+	return fmt.Sprintf("%s %s %s", ctx.Method(), ctx.Path(), user)
+}
+
+// requestFingerprint hashes what makes two requests the same
+func requestFingerprint(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.OriginalURL()))
+	hash.Write([]byte{0})
+	hash.Write(ctx.Body())
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,247 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/entity"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/helpers/http/errorhandler"
+)
+
+// fakeIdempotencyRepository keeps the keys in memory, keys never expire
+type fakeIdempotencyRepository struct {
+	keys map[string]entity.IdempotencyKey
+}
+
+func (r *fakeIdempotencyRepository) Claim(_ context.Context, key *entity.IdempotencyKey, _ time.Duration) (bool, error) {
+	if _, ok := r.keys[key.Scope+" "+key.Key]; ok {
+		return false, nil
+	}
+
+	r.keys[key.Scope+" "+key.Key] = *key
+	return true, nil
+}
+
+func (r *fakeIdempotencyRepository) FindByKey(_ context.Context, key, scope string) (entity.IdempotencyKey, error) {
+	stored, ok := r.keys[scope+" "+key]
+	if !ok {
+		return entity.IdempotencyKey{}, sql.ErrNoRows
+	}
+
+	return stored, nil
+}
+
+func (r *fakeIdempotencyRepository) Complete(_ context.Context, key *entity.IdempotencyKey) error {
+	key.CompletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	r.keys[key.Scope+" "+key.Key] = *key
+	return nil
+}
+
+func (r *fakeIdempotencyRepository) Release(_ context.Context, key, scope string) error {
+	if !r.keys[scope+" "+key].CompletedAt.Valid {
+		delete(r.keys, scope+" "+key)
+	}
+
+	return nil
+}
+
+func (r *fakeIdempotencyRepository) DeleteExpired(context.Context, time.Duration) (int64, error) {
+	return 0, nil
+}
+
+type idempotencyFixture struct {
+	app   *fiber.App
+	repo  *fakeIdempotencyRepository
+	calls int
+	fail  bool
+}
+
+// newIdempotencyFixture serves POST /purchase, which answers with how many
+// times it ran, or with a server error while fail is set
+func newIdempotencyFixture() *idempotencyFixture {
+	f := &idempotencyFixture{repo: &fakeIdempotencyRepository{keys: map[string]entity.IdempotencyKey{}}}
+	m := NewMiddleware(nil, nil, f.repo, time.Hour)
+
+	f.app = fiber.New(fiber.Config{ErrorHandler: errorhandler.ErrorHandler})
+	f.app.Post("/purchase", m.Idempotency(), func(ctx *fiber.Ctx) error {
+		f.calls++
+		if f.fail {
+			return fiber.NewError(fiber.StatusInternalServerError, "database is down")
+		}
+
+		return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{"call": f.calls})
+	})
+
+	return f
+}
+
+func (f *idempotencyFixture) post(t *testing.T, key string, body string, headers ...string) (*http.Response, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(fiber.MethodPost, "/purchase", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	res, err := f.app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	resBody, _ := io.ReadAll(res.Body)
+	return res, string(resBody)
+}
+
+// fingerprintOf returns the fingerprint of a POST /purchase with the body
+func fingerprintOf(t *testing.T, body string) string {
+	t.Helper()
+
+	var fingerprint string
+	app := fiber.New()
+	app.Post("/purchase", func(ctx *fiber.Ctx) error {
+		fingerprint = requestFingerprint(ctx)
+		return nil
+	})
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/purchase", strings.NewReader(body))); err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	return fingerprint
+}
+
+func TestIdempotencyReplaysTheStoredResponse(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	first, firstBody := f.post(t, "key-1", `{"qty":2}`)
+	if first.StatusCode != fiber.StatusCreated {
+		t.Fatalf("first request status = %d, want %d", first.StatusCode, fiber.StatusCreated)
+	}
+
+	retry, retryBody := f.post(t, "key-1", `{"qty":2}`)
+	if retry.StatusCode != fiber.StatusCreated || retryBody != firstBody {
+		t.Errorf("retry = %d %s, want %d %s", retry.StatusCode, retryBody, fiber.StatusCreated, firstBody)
+	}
+
+	if retry.Header.Get(IdempotencyReplayedHeader) != "true" || first.Header.Get(IdempotencyReplayedHeader) != "" {
+		t.Errorf("%s header = %q then %q, want it on the replay only", IdempotencyReplayedHeader, first.Header.Get(IdempotencyReplayedHeader), retry.Header.Get(IdempotencyReplayedHeader))
+	}
+
+	if retry.Header.Get(fiber.HeaderContentType) != first.Header.Get(fiber.HeaderContentType) {
+		t.Errorf("replayed content type = %q, want %q", retry.Header.Get(fiber.HeaderContentType), first.Header.Get(fiber.HeaderContentType))
+	}
+
+	if f.calls != 1 {
+		t.Errorf("handler ran %d times, want once", f.calls)
+	}
+}
+
+func TestIdempotencyConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(f *idempotencyFixture, scope string)
+		body     string
+		wantBody string
+	}{
+		{
+			name: "same key with another body",
+			setup: func(f *idempotencyFixture, _ string) {
+				f.post(t, "key-1", `{"qty":2}`)
+			},
+			body:     `{"qty":3}`,
+			wantBody: "different request",
+		},
+		{
+			name: "first request still running",
+			setup: func(f *idempotencyFixture, scope string) {
+				f.repo.keys[scope+" key-1"] = entity.IdempotencyKey{Key: "key-1", Scope: scope, RequestHash: fingerprintOf(t, `{"qty":2}`)}
+			},
+			body:     `{"qty":2}`,
+			wantBody: "still being processed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newIdempotencyFixture()
+			tt.setup(f, "POST /purchase guest:0.0.0.0")
+			calls := f.calls
+
+			res, body := f.post(t, "key-1", tt.body)
+			if res.StatusCode != fiber.StatusConflict || !strings.Contains(body, tt.wantBody) {
+				t.Fatalf("response = %d %s, want %d %s", res.StatusCode, body, fiber.StatusConflict, tt.wantBody)
+			}
+
+			if f.calls != calls {
+				t.Errorf("handler ran for a conflicting request")
+			}
+		})
+	}
+}
+
+func TestIdempotencyRunsAgainAfterServerErrors(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	f.fail = true
+	res, body := f.post(t, "key-1", `{"qty":2}`)
+	if res.StatusCode != fiber.StatusInternalServerError {
+		t.Fatalf("failing request status = %d %s, want %d", res.StatusCode, body, fiber.StatusInternalServerError)
+	}
+
+	f.fail = false
+	res, _ = f.post(t, "key-1", `{"qty":2}`)
+	if res.StatusCode != fiber.StatusCreated || res.Header.Get(IdempotencyReplayedHeader) != "" {
+		t.Errorf("retry status = %d replayed %q, want a fresh %d", res.StatusCode, res.Header.Get(IdempotencyReplayedHeader), fiber.StatusCreated)
+	}
+
+	if f.calls != 2 {
+		t.Errorf("handler ran %d times, want twice", f.calls)
+	}
+}
+
+func TestIdempotencyKeysAreScoped(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	f.post(t, "key-1", `{"qty":2}`, LookupTokenHeader, "token-of-one-guest")
+	_, body := f.post(t, "key-1", `{"qty":2}`, LookupTokenHeader, "token-of-another-guest")
+
+	if f.calls != 2 || !strings.Contains(body, `"call":2`) {
+		t.Errorf("handler ran %d times and answered %s, want the second guest to get their own response", f.calls, body)
+	}
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	for i := 1; i <= 2; i++ {
+		res, body := f.post(t, "", `{"qty":2}`)
+		if res.StatusCode != fiber.StatusCreated || !strings.Contains(body, `"call":`+strconv.Itoa(i)) {
+			t.Errorf("request %d = %d %s, want the handler to run", i, res.StatusCode, body)
+		}
+	}
+
+	if len(f.repo.keys) != 0 {
+		t.Errorf("stored keys %v for requests without a key", f.repo.keys)
+	}
+}
+
+func TestIdempotencyRejectsLongKeys(t *testing.T) {
+	f := newIdempotencyFixture()
+
+	res, _ := f.post(t, strings.Repeat("k", maxIdempotencyKeyLength+1), `{"qty":2}`)
+	if res.StatusCode != fiber.StatusBadRequest || f.calls != 0 {
+		t.Errorf("status = %d with %d handler runs, want %d without running it", res.StatusCode, f.calls, fiber.StatusBadRequest)
+	}
+}
@@ -1,15 +1,33 @@
 package middlewares
 
-import "github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+import (
+	"time"
+
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain/contracts"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/jwt"
+)
+
+// LookupTokenHeader carries the lookup token guests got for their purchase at
+// checkout. It isn't read from the query so it doesn't end up in access logs.
+const LookupTokenHeader = "X-Lookup-Token"
 
 type Middleware struct {
-	jwt jwt.JwtInterface
+	jwt                   jwt.JwtInterface
+	sessionRepository     contracts.SessionRepository
+	idempotencyRepository contracts.IdempotencyRepository
+	idempotencyKeyTTL     time.Duration
 }
 
 func NewMiddleware(
 	jwt jwt.JwtInterface,
+	sessionRepository contracts.SessionRepository,
+	idempotencyRepository contracts.IdempotencyRepository,
+	idempotencyKeyTTL time.Duration,
 ) *Middleware {
 	return &Middleware{
-		jwt: jwt,
+		jwt:                   jwt,
+		sessionRepository:     sessionRepository,
+		idempotencyRepository: idempotencyRepository,
+		idempotencyKeyTTL:     idempotencyKeyTTL,
 	}
 }
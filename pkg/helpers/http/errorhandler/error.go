@@ -1 +1,44 @@
 package errorhandler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/helpers/http/response"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/validator"
+)
+
+// ErrorHandler translates errors returned by handlers into HTTP responses
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	var requestErr *domain.RequestError
+	if errors.As(err, &requestErr) {
+		return response.SendResponse(ctx, requestErr.StatusCode, requestErr)
+	}
+
+	var valErr validator.ValidationErrors
+	if errors.As(err, &valErr) {
+		return response.SendResponse(ctx, fiber.StatusBadRequest, valErr)
+	}
+
+	code := fiber.StatusInternalServerError
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		code = fiberErr.Code
+	}
+
+	if code >= fiber.StatusInternalServerError {
+		log.Error(log.LogInfo{
+			"error":  err.Error(),
+			"method": ctx.Method(),
+			"path":   ctx.Path(),
+		}, "[ErrorHandler] unhandled server error")
+
+		// The message may carry SQL or driver details, only the log gets them
+		return response.SendResponse(ctx, code, fiber.NewError(code))
+	}
+
+	return response.SendResponse(ctx, code, err)
+}
@@ -0,0 +1,66 @@
+package errorhandler
+
+import (
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/domain"
+)
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantBody string
+	}{
+		{
+			name:     "client error keeps its message",
+			err:      fiber.NewError(fiber.StatusConflict, "payment was already refunded"),
+			wantCode: fiber.StatusConflict,
+			wantBody: `{"payload":{"error":"payment was already refunded"}}`,
+		},
+		{
+			name:     "domain error",
+			err:      domain.ErrEntityNotFound,
+			wantCode: fiber.StatusNotFound,
+		},
+		{
+			name:     "server error hides its message",
+			err:      fiber.NewError(fiber.StatusInternalServerError, `pq: relation "purchase" does not exist`),
+			wantCode: fiber.StatusInternalServerError,
+			wantBody: `{"payload":{"error":"Internal Server Error"}}`,
+		},
+		{
+			name:     "plain error hides its message",
+			err:      errors.New("dial tcp 10.0.0.3:5432: connection refused"),
+			wantCode: fiber.StatusInternalServerError,
+			wantBody: `{"payload":{"error":"Internal Server Error"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+			app.Get("/", func(ctx *fiber.Ctx) error { return tt.err })
+
+			res, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+
+			body, _ := io.ReadAll(res.Body)
+			if res.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d", res.StatusCode, tt.wantCode)
+			}
+
+			if tt.wantBody != "" && strings.TrimSpace(string(body)) != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -1,38 +1,74 @@
 package jwt
 
 import (
-	"strconv"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/internal/infra/env"
 )
 
+var ErrNoSigningKey = errors.New("no private key found for the signing key id")
+
 type JwtInterface interface {
-	Create(userID int) (string, error)
+	Create(userID int, sessionID string) (string, error)
 	Decode(tokenString string, claims *Claims) error
+	// JWKS returns the public keys other services verify tokens with
+	JWKS() JWKS
 }
 
+// Claims of an access token. The registered ID (jti) is the session the token
+// was issued for, revoking the session revokes the token.
 type Claims struct {
 	jwt.RegisteredClaims
 	UserID int `json:"user_id"`
 }
 
+// JwtStruct signs tokens with the key SigningKeyID picks out of Keys and
+// verifies them with the key named by their kid header, so retired keys can
+// stay in Keys until the tokens they signed have expired. Without Keys tokens
+// are signed with SecretKey using HS256.
 type JwtStruct struct {
-	SecretKey   string
-	ExpiredTime time.Duration
+	SecretKey    string
+	ExpiredTime  time.Duration
+	Keys         map[string]*Key
+	SigningKeyID string
+}
+
+// Config of the tokens. Without KeysDir tokens are signed with SecretKey.
+type Config struct {
+	SecretKey    string
+	ExpiredTime  time.Duration
+	KeysDir      string
+	SigningKeyID string
 }
 
-var Jwt = getJwt()
+func New(config Config) (JwtInterface, error) {
+	j := &JwtStruct{
+		SecretKey:   config.SecretKey,
+		ExpiredTime: config.ExpiredTime,
+	}
+
+	if config.KeysDir == "" {
+		return j, nil
+	}
+
+	keys, err := LoadKeys(config.KeysDir)
+	if err != nil {
+		return nil, err
+	}
 
-func getJwt() JwtInterface {
-	return &JwtStruct{
-		SecretKey:   env.AppEnv.JwtSecretKey,
-		ExpiredTime: env.AppEnv.JwtExpTime,
+	signingKey, ok := keys[config.SigningKeyID]
+	if !ok || signingKey.PrivateKey == nil {
+		return nil, ErrNoSigningKey
 	}
+
+	j.Keys = keys
+	j.SigningKeyID = config.SigningKeyID
+
+	return j, nil
 }
 
-func (j *JwtStruct) Create(userID int) (string, error) {
+func (j *JwtStruct) Create(userID int, sessionID string) (string, error) {
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer: "tutuplapak",
@@ -41,13 +77,21 @@ func (j *JwtStruct) Create(userID int) (string, error) {
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.ExpiredTime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			ID:        strconv.Itoa(userID),
+			ID:        sessionID,
 		},
 		UserID: userID,
 	}
 
-	unsignedJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedJWT, err := unsignedJWT.SignedString([]byte(j.SecretKey))
+	if len(j.Keys) == 0 {
+		unsignedJWT := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return unsignedJWT.SignedString([]byte(j.SecretKey))
+	}
+
+	key := j.Keys[j.SigningKeyID]
+	unsignedJWT := jwt.NewWithClaims(key.Method, claims)
+	unsignedJWT.Header["kid"] = key.ID
+
+	signedJWT, err := unsignedJWT.SignedString(key.PrivateKey)
 	if err != nil {
 		return "", err
 	}
@@ -56,10 +100,12 @@ func (j *JwtStruct) Create(userID int) (string, error) {
 }
 
 func (j *JwtStruct) Decode(tokenString string, claims *Claims) error {
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(_ *jwt.Token) (any, error) {
-		return []byte(j.SecretKey), nil
-	})
+	validMethods := []string{jwt.SigningMethodHS256.Alg()}
+	if len(j.Keys) > 0 {
+		validMethods = []string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}
+	}
 
+	token, err := jwt.ParseWithClaims(tokenString, claims, j.verificationKey, jwt.WithValidMethods(validMethods))
 	if err != nil {
 		return err
 	}
@@ -70,3 +116,27 @@ func (j *JwtStruct) Decode(tokenString string, claims *Claims) error {
 
 	return nil
 }
+
+func (j *JwtStruct) JWKS() JWKS {
+	return newJWKS(j.Keys)
+}
+
+// verificationKey picks the key by the kid header of the token. The algorithm
+// has to be the one of the key, so a token can't pick a weaker one.
+func (j *JwtStruct) verificationKey(token *jwt.Token) (any, error) {
+	if len(j.Keys) == 0 {
+		return []byte(j.SecretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.Keys[kid]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+
+	return key.PublicKey, nil
+}
@@ -0,0 +1,133 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKeyID   = errors.New("unknown key id")
+	ErrUnsupportedKey = errors.New("unsupported key, expected an RSA or Ed25519 key in PEM format")
+)
+
+// Key is an RSA (RS256) or Ed25519 (EdDSA) key. PrivateKey is nil for keys
+// that only verify tokens, e.g. the public half of a retired key.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+// LoadKeys reads every .pem file in dir. The file name without its extension
+// is the kid of the key.
+func LoadKeys(dir string) (map[string]*Key, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*Key, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		key, err := ParseKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		keys[id] = key
+	}
+
+	return keys, nil
+}
+
+// ParseKey parses a PEM encoded private or public key
+func ParseKey(id string, data []byte) (*Key, error) {
+	if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, PrivateKey: privateKey, PublicKey: &privateKey.PublicKey}, nil
+	}
+
+	if privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		if edKey, ok := privateKey.(ed25519.PrivateKey); ok {
+			return &Key{ID: id, Method: jwt.SigningMethodEdDSA, PrivateKey: edKey, PublicKey: edKey.Public()}, nil
+		}
+	}
+
+	if publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, PublicKey: publicKey}, nil
+	}
+
+	if publicKey, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+		if edKey, ok := publicKey.(ed25519.PublicKey); ok {
+			return &Key{ID: id, Method: jwt.SigningMethodEdDSA, PublicKey: edKey}, nil
+		}
+	}
+
+	return nil, ErrUnsupportedKey
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517)
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is the public half of a key, with the RSA (n, e) or the OKP (crv, x)
+// members filled in
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+func newJWKS(keys map[string]*Key) JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range keys {
+		jwk := JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+		}
+
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	sort.Slice(jwks.Keys, func(i, j int) bool {
+		return jwks.Keys[i].Kid < jwks.Keys[j].Kid
+	})
+
+	return jwks
+}
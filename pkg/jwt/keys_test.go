@@ -0,0 +1,244 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func encodePEM(t *testing.T, blockType string, der []byte, err error) []byte {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("failed to marshal %s: %v", blockType, err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+}
+
+func generateKeys(t *testing.T) (*rsa.PrivateKey, ed25519.PrivateKey) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ed25519 key: %v", err)
+	}
+
+	return rsaKey, edKey
+}
+
+func TestParseKey(t *testing.T) {
+	rsaKey, edKey := generateKeys(t)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ecdsa key: %v", err)
+	}
+
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	edPKCS8, edErr := x509.MarshalPKCS8PrivateKey(edKey)
+	ecPKCS8, ecErr := x509.MarshalPKCS8PrivateKey(ecKey)
+	rsaPublic, rsaPublicErr := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	edPublic, edPublicErr := x509.MarshalPKIXPublicKey(edKey.Public())
+
+	tests := []struct {
+		name        string
+		data        []byte
+		wantMethod  jwt.SigningMethod
+		wantPrivate bool
+		wantPublic  crypto.PublicKey
+		wantErr     error
+	}{
+		{
+			name:        "rsa private key in pkcs1",
+			data:        encodePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), nil),
+			wantMethod:  jwt.SigningMethodRS256,
+			wantPrivate: true,
+			wantPublic:  &rsaKey.PublicKey,
+		},
+		{
+			name:        "rsa private key in pkcs8",
+			data:        encodePEM(t, "PRIVATE KEY", rsaPKCS8, err),
+			wantMethod:  jwt.SigningMethodRS256,
+			wantPrivate: true,
+			wantPublic:  &rsaKey.PublicKey,
+		},
+		{
+			name:        "ed25519 private key",
+			data:        encodePEM(t, "PRIVATE KEY", edPKCS8, edErr),
+			wantMethod:  jwt.SigningMethodEdDSA,
+			wantPrivate: true,
+			wantPublic:  edKey.Public(),
+		},
+		{
+			name:       "rsa public key",
+			data:       encodePEM(t, "PUBLIC KEY", rsaPublic, rsaPublicErr),
+			wantMethod: jwt.SigningMethodRS256,
+			wantPublic: &rsaKey.PublicKey,
+		},
+		{
+			name:       "ed25519 public key",
+			data:       encodePEM(t, "PUBLIC KEY", edPublic, edPublicErr),
+			wantMethod: jwt.SigningMethodEdDSA,
+			wantPublic: edKey.Public(),
+		},
+		{
+			name:    "ecdsa key",
+			data:    encodePEM(t, "PRIVATE KEY", ecPKCS8, ecErr),
+			wantErr: ErrUnsupportedKey,
+		},
+		{
+			name:    "not pem",
+			data:    []byte("not a key"),
+			wantErr: ErrUnsupportedKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey("key-1", tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseKey() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if key.ID != "key-1" {
+				t.Errorf("ParseKey() id = %q, want %q", key.ID, "key-1")
+			}
+
+			if key.Method.Alg() != tt.wantMethod.Alg() {
+				t.Errorf("ParseKey() alg = %s, want %s", key.Method.Alg(), tt.wantMethod.Alg())
+			}
+
+			if (key.PrivateKey != nil) != tt.wantPrivate {
+				t.Errorf("ParseKey() has private key = %v, want %v", key.PrivateKey != nil, tt.wantPrivate)
+			}
+
+			publicKey, ok := key.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+			if !ok || !publicKey.Equal(tt.wantPublic) {
+				t.Errorf("ParseKey() returned another public key")
+			}
+		})
+	}
+}
+
+func TestVerificationKey(t *testing.T) {
+	rsaKey, edKey := generateKeys(t)
+
+	j := &JwtStruct{
+		Keys: map[string]*Key{
+			"rsa": {ID: "rsa", Method: jwt.SigningMethodRS256, PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey},
+			"ed":  {ID: "ed", Method: jwt.SigningMethodEdDSA, PrivateKey: edKey, PublicKey: edKey.Public()},
+		},
+		SigningKeyID: "rsa",
+	}
+
+	tests := []struct {
+		name    string
+		j       *JwtStruct
+		method  jwt.SigningMethod
+		header  map[string]any
+		want    any
+		wantErr error
+	}{
+		{
+			name:   "rsa key",
+			j:      j,
+			method: jwt.SigningMethodRS256,
+			header: map[string]any{"kid": "rsa"},
+			want:   &rsaKey.PublicKey,
+		},
+		{
+			name:   "ed25519 key",
+			j:      j,
+			method: jwt.SigningMethodEdDSA,
+			header: map[string]any{"kid": "ed"},
+			want:   edKey.Public(),
+		},
+		{
+			name:    "alg of another key",
+			j:       j,
+			method:  jwt.SigningMethodEdDSA,
+			header:  map[string]any{"kid": "rsa"},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "hmac alg with an rsa kid",
+			j:       j,
+			method:  jwt.SigningMethodHS256,
+			header:  map[string]any{"kid": "rsa"},
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "unknown kid",
+			j:       j,
+			method:  jwt.SigningMethodRS256,
+			header:  map[string]any{"kid": "retired"},
+			wantErr: ErrUnknownKeyID,
+		},
+		{
+			name:    "missing kid",
+			j:       j,
+			method:  jwt.SigningMethodRS256,
+			header:  map[string]any{},
+			wantErr: ErrUnknownKeyID,
+		},
+		{
+			name:    "kid that isn't a string",
+			j:       j,
+			method:  jwt.SigningMethodRS256,
+			header:  map[string]any{"kid": 1},
+			wantErr: ErrUnknownKeyID,
+		},
+		{
+			name:   "secret without keys",
+			j:      &JwtStruct{SecretKey: "secret"},
+			method: jwt.SigningMethodHS256,
+			header: map[string]any{},
+			want:   []byte("secret"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := &jwt.Token{Method: tt.method, Header: tt.header}
+
+			got, err := tt.j.verificationKey(token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("verificationKey() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			switch want := tt.want.(type) {
+			case []byte:
+				if string(got.([]byte)) != string(want) {
+					t.Errorf("verificationKey() = %q, want %q", got, want)
+				}
+			case crypto.PublicKey:
+				publicKey, ok := got.(interface{ Equal(crypto.PublicKey) bool })
+				if !ok || !publicKey.Equal(want) {
+					t.Errorf("verificationKey() returned another public key")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount    = errors.New("invalid money amount")
+	ErrTooManyDecimals  = errors.New("money amount has more decimals than its currency allows")
+	ErrCurrencyMismatch = errors.New("money currencies do not match")
+	ErrOverflow         = errors.New("money amount overflows")
+)
+
+// exponents holds the number of minor unit digits of the supported currencies.
+// Currencies missing from the map use two digits.
+var exponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"SGD": 2,
+	"JPY": 0,
+}
+
+// DefaultCurrency is the currency of amounts read from the database and
+// from requests. It is IDR unless SetDefaultCurrency picks another one.
+var DefaultCurrency = "IDR"
+
+// SetDefaultCurrency changes DefaultCurrency. It has to be called at startup,
+// before any amount is read; an empty currency keeps the current one.
+func SetDefaultCurrency(currency string) {
+	if currency == "" {
+		return
+	}
+
+	DefaultCurrency = strings.ToUpper(currency)
+}
+
+// Money is an exact amount of a currency, kept in its minor units. The zero
+// value is zero of no particular currency and can be added to any amount.
+//
+// In JSON it is written as a decimal number in major units, e.g. 10000.50, and
+// in the database as a NUMERIC string.
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// New returns amount minor units of the currency
+func New(amount int64, currency string) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// Parse reads a decimal amount in major units, e.g. "10000.50"
+func Parse(value string, currency string) (Money, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Money{}, ErrInvalidAmount
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	exponent := exponentOf(currency)
+
+	// NUMERIC columns are padded with zeros, those don't add precision
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exponent {
+		return Money{}, ErrTooManyDecimals
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || strings.ContainsAny(whole+fraction, "+-") {
+		return Money{}, ErrInvalidAmount
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return New(amount, currency), nil
+}
+
+// Amount returns the amount in minor units
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// Add returns m + other. It panics when the currencies differ or the sum
+// overflows.
+func (m Money) Add(other Money) Money {
+	currency := m.mustMatch(other)
+	return New(add(m.amount, other.amount), currency)
+}
+
+// Sub returns m - other. It panics when the currencies differ or the
+// difference overflows.
+func (m Money) Sub(other Money) Money {
+	currency := m.mustMatch(other)
+	return New(sub(m.amount, other.amount), currency)
+}
+
+// Mul returns m multiplied by a quantity. It panics when the product overflows.
+func (m Money) Mul(quantity int64) Money {
+	return New(mul(m.amount, quantity), m.currency)
+}
+
+// Percent returns percent percent of m, rounded down to the minor unit. It
+// panics when the amount times percent overflows.
+func (m Money) Percent(percent int64) Money {
+	return New(mul(m.amount, percent)/100, m.currency)
+}
+
+// Cmp returns -1, 0 or +1 when m is less than, equal to or greater than other.
+// It panics when the currencies differ.
+func (m Money) Cmp(other Money) int {
+	m.mustMatch(other)
+
+	switch {
+	case m.amount < other.amount:
+		return -1
+	case m.amount > other.amount:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Float64 returns the amount in major units. It is meant for comparisons such
+// as validation, never for arithmetic.
+func (m Money) Float64() float64 {
+	return float64(m.amount) / math.Pow10(exponentOf(m.currency))
+}
+
+// String returns the amount in major units, e.g. "10000.50"
+func (m Money) String() string {
+	exponent := exponentOf(m.currency)
+
+	amount := m.amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a string holding one
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+
+	if strings.ContainsAny(value, "eE") {
+		return ErrInvalidAmount
+	}
+
+	parsed, err := Parse(value, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// UnmarshalText lets amounts be read from query parameters
+func (m *Money) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text), DefaultCurrency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// Value implements driver.Valuer, amounts are written as NUMERIC strings
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan implements sql.Scanner for NUMERIC and BIGINT columns holding major units
+func (m *Money) Scan(src any) error {
+	var parsed Money
+	var err error
+
+	switch value := src.(type) {
+	case nil:
+		parsed = New(0, DefaultCurrency)
+	case string:
+		parsed, err = Parse(value, DefaultCurrency)
+	case []byte:
+		parsed, err = Parse(string(value), DefaultCurrency)
+	case int64:
+		parsed = New(value*int64(math.Pow10(exponentOf(DefaultCurrency))), DefaultCurrency)
+	case float64:
+		parsed, err = Parse(strconv.FormatFloat(value, 'f', -1, 64), DefaultCurrency)
+	default:
+		return fmt.Errorf("cannot scan %T into money", src)
+	}
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// mustMatch returns the currency shared by both amounts, a zero value Money
+// takes the currency of the other amount
+func (m Money) mustMatch(other Money) string {
+	switch {
+	case m.currency == "":
+		return other.currency
+	case other.currency == "" || m.currency == other.currency:
+		return m.currency
+	default:
+		panic(fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency))
+	}
+}
+
+// add, sub and mul panic instead of wrapping around, a wrapped amount would
+// silently turn a large total negative
+func add(a, b int64) int64 {
+	sum := a + b
+	if (b > 0 && sum < a) || (b < 0 && sum > a) {
+		panic(ErrOverflow)
+	}
+
+	return sum
+}
+
+func sub(a, b int64) int64 {
+	difference := a - b
+	if (b > 0 && difference > a) || (b < 0 && difference < a) {
+		panic(ErrOverflow)
+	}
+
+	return difference
+}
+
+func mul(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		panic(ErrOverflow)
+	}
+
+	return product
+}
+
+func exponentOf(currency string) int {
+	exponent, ok := exponents[currency]
+	if !ok {
+		return 2
+	}
+
+	return exponent
+}
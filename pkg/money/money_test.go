@@ -0,0 +1,170 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		currency string
+		want     int64
+		wantErr  error
+	}{
+		{name: "whole amount", value: "10000", currency: "IDR", want: 1000000},
+		{name: "two decimals", value: "10000.50", currency: "IDR", want: 1000050},
+		{name: "one decimal", value: "0.5", currency: "IDR", want: 50},
+		{name: "no whole part", value: ".25", currency: "USD", want: 25},
+		{name: "negative", value: "-12.34", currency: "USD", want: -1234},
+		{name: "surrounding spaces", value: " 7.00 ", currency: "IDR", want: 700},
+		{name: "numeric padding", value: "15.5000", currency: "IDR", want: 1550},
+		{name: "zero exponent currency", value: "500", currency: "JPY", want: 500},
+		{name: "unknown currency uses two digits", value: "1.23", currency: "EUR", want: 123},
+		{name: "too many decimals", value: "1.234", currency: "IDR", wantErr: ErrTooManyDecimals},
+		{name: "decimals on zero exponent currency", value: "1.5", currency: "JPY", wantErr: ErrTooManyDecimals},
+		{name: "empty", value: "", currency: "IDR", wantErr: ErrInvalidAmount},
+		{name: "not a number", value: "abc", currency: "IDR", wantErr: ErrInvalidAmount},
+		{name: "sign after the minus", value: "-+1", currency: "IDR", wantErr: ErrInvalidAmount},
+		{name: "sign in the fraction", value: "1.-5", currency: "IDR", wantErr: ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.currency)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.value, err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if got.Amount() != tt.want || got.Currency() != tt.currency {
+				t.Errorf("Parse(%q) = %d %s, want %d %s", tt.value, got.Amount(), got.Currency(), tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         Money
+		other     Money
+		want      Money
+		wantPanic error
+	}{
+		{name: "same currency", m: New(150, "IDR"), other: New(250, "IDR"), want: New(400, "IDR")},
+		{name: "negative amount", m: New(150, "IDR"), other: New(-200, "IDR"), want: New(-50, "IDR")},
+		{name: "zero value on the left", m: Money{}, other: New(99, "USD"), want: New(99, "USD")},
+		{name: "zero value on the right", m: New(99, "USD"), other: Money{}, want: New(99, "USD")},
+		{name: "both zero values", m: Money{}, other: Money{}, want: Money{}},
+		{name: "up to the largest amount", m: New(math.MaxInt64-1, "IDR"), other: New(1, "IDR"), want: New(math.MaxInt64, "IDR")},
+		{name: "currency mismatch", m: New(1, "IDR"), other: New(1, "USD"), wantPanic: ErrCurrencyMismatch},
+		{name: "overflow", m: New(math.MaxInt64, "IDR"), other: New(1, "IDR"), wantPanic: ErrOverflow},
+		{name: "negative overflow", m: New(math.MinInt64, "IDR"), other: New(-1, "IDR"), wantPanic: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer expectPanic(t, "Add()", tt.wantPanic)
+
+			got := tt.m.Add(tt.other)
+			if got != tt.want {
+				t.Errorf("Add() = %d %s, want %d %s", got.Amount(), got.Currency(), tt.want.Amount(), tt.want.Currency())
+			}
+		})
+	}
+}
+
+func TestSubOverflow(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     Money
+		other Money
+	}{
+		{name: "below the smallest amount", m: New(math.MinInt64, "IDR"), other: New(1, "IDR")},
+		{name: "subtracting the smallest amount", m: New(0, "IDR"), other: New(math.MinInt64, "IDR")},
+		{name: "above the largest amount", m: New(math.MaxInt64, "IDR"), other: New(-1, "IDR")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer expectPanic(t, "Sub()", ErrOverflow)
+			tt.m.Sub(tt.other)
+		})
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		name      string
+		m         Money
+		quantity  int64
+		want      int64
+		wantPanic error
+	}{
+		{name: "quantity", m: New(1500, "IDR"), quantity: 3, want: 4500},
+		{name: "zero quantity", m: New(math.MaxInt64, "IDR"), quantity: 0, want: 0},
+		{name: "negative quantity", m: New(1500, "IDR"), quantity: -2, want: -3000},
+		{name: "overflow", m: New(math.MaxInt64/2+1, "IDR"), quantity: 2, wantPanic: ErrOverflow},
+		{name: "largest price times a large quantity", m: New(999999999999999, "IDR"), quantity: 1 << 31, wantPanic: ErrOverflow},
+		{name: "smallest amount negated", m: New(math.MinInt64, "IDR"), quantity: -1, wantPanic: ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer expectPanic(t, "Mul()", tt.wantPanic)
+
+			got := tt.m.Mul(tt.quantity)
+			if got.Amount() != tt.want {
+				t.Errorf("Mul(%d) = %d, want %d", tt.quantity, got.Amount(), tt.want)
+			}
+		})
+	}
+}
+
+func expectPanic(t *testing.T, call string, want error) {
+	t.Helper()
+
+	r := recover()
+	if (r != nil) != (want != nil) {
+		t.Fatalf("%s panic = %v, want %v", call, r, want)
+	}
+
+	if err, ok := r.(error); ok && !errors.Is(err, want) {
+		t.Errorf("%s panicked with %v, want %v", call, err, want)
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		name    string
+		m       Money
+		percent int64
+		want    int64
+	}{
+		{name: "exact", m: New(10000, "IDR"), percent: 10, want: 1000},
+		{name: "rounds down", m: New(999, "IDR"), percent: 15, want: 149},
+		{name: "zero percent", m: New(12345, "IDR"), percent: 0, want: 0},
+		{name: "full amount", m: New(12345, "IDR"), percent: 100, want: 12345},
+		{name: "below one minor unit", m: New(3, "IDR"), percent: 10, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.m.Percent(tt.percent)
+			if got.Amount() != tt.want || got.Currency() != tt.m.Currency() {
+				t.Errorf("Percent(%d) = %d %s, want %d %s", tt.percent, got.Amount(), got.Currency(), tt.want, tt.m.Currency())
+			}
+		})
+	}
+}
+
+func TestPercentOverflow(t *testing.T) {
+	defer expectPanic(t, "Percent()", ErrOverflow)
+	New(math.MaxInt64/50, "IDR").Percent(100)
+}
@@ -0,0 +1,71 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrEmptySecretKey = errors.New("signature secret key is empty")
+
+// SignatureInterface signs values so they can be handed to clients and
+// checked when they come back, e.g. purchase lookup tokens for guests
+type SignatureInterface interface {
+	Sign(value string) string
+	Verify(value, signature string) bool
+	// SignWithExpiry returns a token that stops verifying at expiresAt
+	SignWithExpiry(value string, expiresAt time.Time) string
+	VerifyWithExpiry(value, token string) bool
+}
+
+type SignatureStruct struct {
+	SecretKey string
+}
+
+// New fails without a secret key, anyone could forge signatures made with an
+// empty one
+func New(secretKey string) (SignatureInterface, error) {
+	if secretKey == "" {
+		return nil, ErrEmptySecretKey
+	}
+
+	return &SignatureStruct{
+		SecretKey: secretKey,
+	}, nil
+}
+
+func (s *SignatureStruct) Sign(value string) string {
+	mac := hmac.New(sha256.New, []byte(s.SecretKey))
+	mac.Write([]byte(value))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *SignatureStruct) Verify(value, signature string) bool {
+	return hmac.Equal([]byte(s.Sign(value)), []byte(signature))
+}
+
+// SignWithExpiry signs value along with the unix time it expires at. The
+// token is the expiry followed by the signature, separated by a dot.
+func (s *SignatureStruct) SignWithExpiry(value string, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expiry + "." + s.Sign(value+"."+expiry)
+}
+
+func (s *SignatureStruct) VerifyWithExpiry(value, token string) bool {
+	expiry, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return false
+	}
+
+	return s.Verify(value+"."+expiry, signature)
+}
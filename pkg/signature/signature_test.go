@@ -0,0 +1,55 @@
+package signature
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("")
+	if !errors.Is(err, ErrEmptySecretKey) {
+		t.Fatalf("New(\"\") error = %v, want %v", err, ErrEmptySecretKey)
+	}
+}
+
+func TestVerifyWithExpiry(t *testing.T) {
+	s, err := New("secret")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	other, err := New("other secret")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	valid := s.SignWithExpiry("42", time.Now().Add(time.Hour))
+	expiry, signature, _ := strings.Cut(valid, ".")
+
+	tests := []struct {
+		name  string
+		value string
+		token string
+		want  bool
+	}{
+		{name: "valid", value: "42", token: valid, want: true},
+		{name: "another value", value: "43", token: valid},
+		{name: "expired", value: "42", token: s.SignWithExpiry("42", time.Now().Add(-time.Second))},
+		{name: "expiry pushed back", value: "42", token: "99999999999." + signature},
+		{name: "signed with another key", value: "42", token: other.SignWithExpiry("42", time.Now().Add(time.Hour))},
+		{name: "without expiry", value: "42", token: s.Sign("42")},
+		{name: "expiry isn't a number", value: "42", token: "soon." + signature},
+		{name: "without signature", value: "42", token: expiry + "."},
+		{name: "empty", value: "42", token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.VerifyWithExpiry(tt.value, tt.token); got != tt.want {
+				t.Errorf("VerifyWithExpiry(%q, %q) = %v, want %v", tt.value, tt.token, got, tt.want)
+			}
+		})
+	}
+}
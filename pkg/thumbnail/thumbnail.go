@@ -0,0 +1,139 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image dimensions too large")
+)
+
+type ThumbnailInterface interface {
+	Generate(ctx context.Context, src []byte) ([]byte, error)
+}
+
+type ThumbnailStruct struct {
+	// MaxSize is the longest edge of the thumbnail in pixels
+	MaxSize int
+	// Quality is the JPEG encoding quality, 1-100
+	Quality int
+	// MaxPixels guards against decoding images that would exhaust memory
+	MaxPixels int
+	jobs      chan job
+}
+
+type job struct {
+	src    []byte
+	result chan result
+}
+
+type result struct {
+	thumbnail []byte
+	err       error
+}
+
+// New starts a pool of workers that generate thumbnails. At most workers
+// images are decoded at the same time; other callers wait for a free worker.
+func New(maxSize, quality, maxPixels, workers int) *ThumbnailStruct {
+	if workers < 1 {
+		workers = 1
+	}
+
+	t := &ThumbnailStruct{
+		MaxSize:   maxSize,
+		Quality:   quality,
+		MaxPixels: maxPixels,
+		jobs:      make(chan job),
+	}
+
+	for i := 0; i < workers; i++ {
+		go t.work()
+	}
+
+	return t
+}
+
+// Generate returns a downscaled copy of src encoded in the same format
+func (t *ThumbnailStruct) Generate(ctx context.Context, src []byte) ([]byte, error) {
+	j := job{
+		src:    src,
+		result: make(chan result, 1),
+	}
+
+	select {
+	case t.jobs <- j:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case res := <-j.result:
+		return res.thumbnail, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (t *ThumbnailStruct) work() {
+	for j := range t.jobs {
+		thumbnail, err := t.generate(j.src)
+		j.result <- result{thumbnail, err}
+	}
+}
+
+func (t *ThumbnailStruct) generate(src []byte) ([]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	if format != "jpeg" && format != "png" {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if t.MaxPixels > 0 && cfg.Width*cfg.Height > t.MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := t.scale(cfg.Width, cfg.Height)
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.BiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var buf bytes.Buffer
+	if format == "png" {
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: t.Quality})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// scale fits width and height inside MaxSize while keeping the aspect ratio
+func (t *ThumbnailStruct) scale(width, height int) (int, int) {
+	if t.MaxSize <= 0 || (width <= t.MaxSize && height <= t.MaxSize) {
+		return width, height
+	}
+
+	if width >= height {
+		return t.MaxSize, max(1, height*t.MaxSize/width)
+	}
+
+	return max(1, width*t.MaxSize/height), t.MaxSize
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	enTranslations "github.com/go-playground/validator/v10/translations/en"
 	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/log"
+	"github.com/projectsprintdev-mikroserpis01/tutuplapak-api/pkg/money"
 )
 
 type ValidatorInterface interface {
@@ -43,6 +44,12 @@ func getValidator() ValidatorInterface {
 		// }, "[VALIDATOR][getValidator] Failed to register default translations")
 	}
 
+	// Money is validated by its amount in major units, so `min=100` reads the
+	// same as it would for a number
+	validator.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		return field.Interface().(money.Money).Float64()
+	}, money.Money{})
+
 	return &ValidatorStruct{
 		validator: validator,
 		trans:     trans,
@@ -78,40 +85,32 @@ func (v *ValidatorStruct) Validate(data interface{}) ValidationErrors {
 				tag, fieldName := getTagAndFieldName(field)
 
 				if tag == "json" {
-					body.Fields = map[string]FieldError{
-						fieldName: {
-							Tag:     err.Tag(),
-							Message: err.Translate(v.trans),
-						},
+					body.Fields[fieldName] = FieldError{
+						Tag:     err.Tag(),
+						Message: err.Translate(v.trans),
 					}
 					continue
 				}
 
 				if tag == "param" {
-					param.Fields = map[string]FieldError{
-						fieldName: {
-							Tag:     err.Tag(),
-							Message: err.Translate(v.trans),
-						},
+					param.Fields[fieldName] = FieldError{
+						Tag:     err.Tag(),
+						Message: err.Translate(v.trans),
 					}
 					continue
 				}
 
 				if tag == "query" {
-					query.Fields = map[string]FieldError{
-						fieldName: {
-							Tag:     err.Tag(),
-							Message: err.Translate(v.trans),
-						},
+					query.Fields[fieldName] = FieldError{
+						Tag:     err.Tag(),
+						Message: err.Translate(v.trans),
 					}
 					continue
 				}
 
-				other.Fields = map[string]FieldError{
-					fieldName: {
-						Tag:     err.Tag(),
-						Message: err.Translate(v.trans),
-					},
+				other.Fields[fieldName] = FieldError{
+					Tag:     err.Tag(),
+					Message: err.Translate(v.trans),
 				}
 			}
 